@@ -3,101 +3,148 @@ package cli
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/willmadison/donately-sync-tools/donately"
 	donatelyhttp "github.com/willmadison/donately-sync-tools/donately/http"
+	"github.com/willmadison/donately-sync-tools/donately/http/fake"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const epsilon = 1e-9
+// minimumUnrecordedGift is the smallest gap between the collection report and
+// Donately that backfill treats as a missing gift rather than rounding.
+var minimumUnrecordedGift = donately.NewMoney(50, "")
 
 // Environment provides an abstraction around the execution environment
 type Environment struct {
 	Stderr io.Writer
 	Stdout io.Writer
 	Stdin  io.Reader
-	Files  embed.FS
+	Files  fs.FS
 	UI     embed.FS
 }
 
+// CassetteFlags lets a command record its Donately traffic to a cassette, or
+// replay a previously recorded one instead of calling the live API.
+type CassetteFlags struct {
+	Record string `type:"path" xor:"cassette" help:"record every Donately request/response pair to this JSONL cassette."`
+	Replay string `type:"existingfile" xor:"cassette" help:"replay Donately responses from this JSONL cassette instead of calling the API."`
+}
+
+func (f CassetteFlags) cassette() CassetteFlags {
+	return f
+}
+
 type BackfillCmd struct {
-	AccountID  string `required help:"the account id that this backfill should take place in."`
-	CampaignID string `required help:"the campaign id that this backfill should take place in."`
+	AccountID  string `required:"" aliases:"account" help:"the account (id, subdomain or title) that this backfill should take place in."`
+	CampaignID string `required:"" help:"the campaign id that this backfill should take place in."`
+	Ledger     string `type:"path" default:"donately-ledger.db" help:"the local SQLite ledger that makes donation creates safe to retry and rerun."`
+
+	MetricsTextfile string `name:"metrics-textfile" type:"path" help:"write Prometheus metrics for this run to a file for node_exporter's textfile collector."`
+
+	CassetteFlags
 }
 
-func (cmd *BackfillCmd) Run(env *Environment, client donatelyhttp.Client, adjustmentStore donately.AdjustmentStore) error {
-	account, err := client.FindAccount(cmd.AccountID)
+func (cmd *BackfillCmd) Run(ctx context.Context, env *Environment, logger *slog.Logger, client donatelyhttp.Client, adjustmentStore donately.AdjustmentStore, rates donately.ExchangeRates) (err error) {
+	recordsByFailureReason := map[string][]donately.CollectionReportRecord{}
+
+	defer func() {
+		recordBackfillRun(err != nil || len(recordsByFailureReason) > 0)
+
+		if cmd.MetricsTextfile != "" {
+			if err := writeMetricsTextfile(cmd.MetricsTextfile); err != nil {
+				logger.Error("failed to write metrics", "path", cmd.MetricsTextfile, "error", err)
+			}
+		}
+	}()
+
+	ctx, span := tracer.Start(ctx, "backfill", trace.WithAttributes(
+		attribute.String("donately.account_id", cmd.AccountID),
+		attribute.String("donately.campaign_id", cmd.CampaignID),
+	))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	account, err := donatelyhttp.ResolveAccount(ctx, client, cmd.AccountID)
 	if err != nil {
-		panic(err.Error())
+		return fmt.Errorf("failed to resolve account %q: %w", cmd.AccountID, err)
 	}
 
-	campaign, err := client.FindCampaign(cmd.CampaignID, account)
+	campaign, err := client.FindCampaign(ctx, cmd.CampaignID, account)
 	if err != nil {
-		panic(err.Error())
+		return fmt.Errorf("failed to find campaign %q: %w", cmd.CampaignID, err)
 	}
 
 	in, err := env.Files.Open("static/inputs/records.csv")
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open the collection report: %w", err)
 	}
 
-	var allDonors []donately.Person
+	collectionRecords, err := donately.ParseCollectionReportCSV(in)
 
-	offset := 0
-	limit := 100
+	if err != nil {
+		logger.ErrorContext(ctx, "encountered an error reading the collection report", "error", err)
+		return err
+	}
 
-	for {
-		donors, err := client.ListPeople(account, offset, limit)
-		if err != nil {
-			return err
-		}
+	ledgerPath := cmd.Ledger
 
-		if len(donors) == 0 {
-			break
+	// A replay's donations were never created, so recording them in the real
+	// ledger would make later live runs skip gifts Donately doesn't have.
+	if cmd.Replay != "" {
+		dir, err := os.MkdirTemp("", "donately-replay-")
+		if err != nil {
+			return fmt.Errorf("failed to create a ledger for the replay: %w", err)
 		}
+		defer os.RemoveAll(dir)
 
-		allDonors = append(allDonors, donors...)
-		offset += len(donors) + 1
+		ledgerPath = filepath.Join(dir, "ledger.db")
 	}
 
-	donorsByEmailAddress := map[string]donately.Person{}
-	donorsByPersonID := map[string]donately.Person{}
-
-	for _, donor := range allDonors {
-		donorsByEmailAddress[strings.ToLower(donor.Email)] = donor
-		donorsByPersonID[donor.ID] = donor
+	ledger, err := donately.NewDonationLedger(ctx, ledgerPath)
+	if err != nil {
+		return err
 	}
+	defer ledger.Close()
 
-	var allDonations []donately.Donation
+	emails := make([]string, 0, len(collectionRecords))
 
-	offset = 0
-	limit = 100
-
-	for {
-		donations, err := client.ListDonations(account, offset, limit)
-		if err != nil {
-			return err
-		}
+	for _, c := range collectionRecords {
+		emails = append(emails, c.EmailAddress)
+	}
 
-		if len(donations) == 0 {
-			break
-		}
+	donorsByEmailAddress, err := donatelyhttp.PeopleByEmail(ctx, client, account, emails)
+	if err != nil {
+		return err
+	}
 
-		allDonations = append(allDonations, donations...)
-		offset += len(donations) + 1
+	allDonations, err := donatelyhttp.Collect(client.Donations(ctx, account, donatelyhttp.DonationFilter{CampaignID: campaign.ID}))
+	if err != nil {
+		return err
 	}
 
+	// Gifts are reconciled against the donor who gave them, not the owner of
+	// the fundraiser they came through: crediting the owner would make the
+	// donor look short and backfill a cash gift duplicating real money.
 	donationsByPersonId := map[string][]donately.Donation{}
 
 	for _, donation := range allDonations {
@@ -108,17 +155,12 @@ func (cmd *BackfillCmd) Run(env *Environment, client donatelyhttp.Client, adjust
 		donationsByPersonId[donation.Person.ID] = append(donationsByPersonId[donation.Person.ID], donation)
 	}
 
-	collectionRecords, err := donately.ParseCollectionReportCSV(in)
+	currency := account.ReportingCurrency()
 
-	if err != nil {
-		return err
-	}
-
-	recordsByFailureReason := map[string][]donately.CollectionReportRecord{}
-
-	for _, c := range collectionRecords {
+	// syncRecord reconciles one collection report row with Donately.
+	syncRecord := func(ctx context.Context, c donately.CollectionReportRecord) error {
 		if person, present := donorsByEmailAddress[strings.ToLower(c.EmailAddress)]; !present {
-			fmt.Printf("%v %v is missing in Donately, adding them in...\n", c.FirstName, c.LastName)
+			logger.InfoContext(ctx, "person is missing in Donately, adding them in", "first_name", c.FirstName, "last_name", c.LastName)
 
 			p := donately.Person{
 				Accounts:  []donately.Account{account},
@@ -127,130 +169,263 @@ func (cmd *BackfillCmd) Run(env *Environment, client donatelyhttp.Client, adjust
 				Email:     c.EmailAddress,
 			}
 
-			savedPerson, err := client.SavePerson(p)
+			savedPerson, err := client.SavePerson(ctx, p)
 			if err != nil {
-				fmt.Printf("Encountered an error saving this person: %+v. Skipping...\n", p)
+				if errors.Is(err, donatelyhttp.ErrUnauthorized) {
+					return err
+				}
 
-				if _, accountedFor := recordsByFailureReason[err.Error()]; !accountedFor {
-					recordsByFailureReason[err.Error()] = []donately.CollectionReportRecord{}
+				logger.WarnContext(ctx, "encountered an error saving this person, skipping", "first_name", p.FirstName, "last_name", p.LastName, "error", err)
 
-				}
-				recordsByFailureReason[err.Error()] = append(recordsByFailureReason[err.Error()], c)
-				continue
+				reason := donatelyhttp.FailureReason(err)
+				recordsByFailureReason[reason] = append(recordsByFailureReason[reason], c)
+				backfillFailures.WithLabelValues(donatelyhttp.FailureClass(err)).Inc()
+				return nil
 			}
 
-			fmt.Printf("%v %v saved (personId=%v)\n", c.FirstName, c.LastName, savedPerson.ID)
+			backfillPersonsCreated.Inc()
+			logger.InfoContext(ctx, "person saved", "first_name", c.FirstName, "last_name", c.LastName, "person_id", savedPerson.ID)
 		} else {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("donately.person_id", person.ID))
+
 			// See how much of a delta there is between their historical total donations and what the record says they've given
 			donations := donationsByPersonId[person.ID]
 
 			// Handle any donation adjustments (i.e. program/fundraisers this brother may have participated in)
 
-			adjustments, err := adjustmentStore.GetAdustmentsByPerson(context.Background(), person)
+			adjustments, err := adjustmentStore.GetAdustmentsByPerson(ctx, person)
 			if err == nil && len(adjustments) != len(c.Adjustments) {
-				fmt.Printf("there's an adjustment discrepancy for %v %v let's update our data based on the official record.\n", c.FirstName, c.LastName)
-				err := adjustmentStore.SaveAdjustments(context.Background(), person, c.Adjustments)
+				logger.InfoContext(ctx, "adjustment discrepancy, updating from the official record", "first_name", c.FirstName, "last_name", c.LastName)
+				err := adjustmentStore.SaveAdjustments(ctx, person, c.Adjustments)
 				if err != nil {
-					fmt.Printf("encounterd an error processing adjustments for %v %v, will retry later. (%v)\n", c.FirstName, c.LastName, err.Error())
+					logger.WarnContext(ctx, "encountered an error saving adjustments, will retry later", "first_name", c.FirstName, "last_name", c.LastName, "error", err)
+				} else {
+					backfillAdjustmentsUpdated.Inc()
 				}
 			} else if err != nil {
-				fmt.Printf("encounterd an error processing adjustments for %v %v, skipping that step for now (%v).\n", c.FirstName, c.LastName, err.Error())
+				logger.WarnContext(ctx, "encountered an error fetching adjustments, skipping that step for now", "first_name", c.FirstName, "last_name", c.LastName, "error", err)
 			}
 
-			var cumulativeDonationInCents int64
+			// Compare like with like: the report row and the gifts, all in the
+			// account's currency.
+			reported, err := c.In(currency, rates)
 
-			for _, donation := range donations {
-				cumulativeDonationInCents += donation.AmountInCents
+			var cumulativeDonation donately.Money
+			if err == nil {
+				cumulativeDonation, err = donately.NetTotal(donations, currency, rates)
 			}
 
-			cumulativeDonation := cumulativeDonationInCents / 100
-			expectedBalanceDue := c.AmountPledged - float64(cumulativeDonation)
+			if err != nil {
+				logger.WarnContext(ctx, "encountered an error converting amounts to the account currency, skipping", "first_name", c.FirstName, "last_name", c.LastName, "currency", currency, "error", err)
 
-			if expectedBalanceDue-c.AmountDue < epsilon || c.AmountDue == 0 {
-				fmt.Printf("According to Donately and/or our records regarding adjustments and other programs, %v %v has actually met their %v pledge with no remaining due.\n", person.FirstName, person.LastName, c.AmountPledged)
-				continue
+				reason := donately.ErrNoExchangeRate.Error()
+				recordsByFailureReason[reason] = append(recordsByFailureReason[reason], c)
+				backfillFailures.WithLabelValues(donatelyhttp.FailureClass(err)).Inc()
+				return nil
 			}
 
-			fmt.Printf("According to Donately, %v %v has actually given %v of their %v pledge with %.2f remaining due (accounting for adjustments and other fundraising programs).\n", person.FirstName, person.LastName, cumulativeDonation, c.AmountPledged, c.AmountDue)
+			expectedBalanceDue := reported.AmountPledged.Sub(cumulativeDonation)
 
-			delta := c.AmountDonated - float64(cumulativeDonation)
+			if expectedBalanceDue.Cmp(reported.AmountDue) <= 0 || reported.AmountDue.IsZero() {
+				logger.InfoContext(ctx, "pledge met, no remaining balance due", "person_id", person.ID, "first_name", person.FirstName, "last_name", person.LastName, "pledged", reported.AmountPledged)
+				return nil
+			}
 
-			if delta > 0 && delta >= .5 {
-				fmt.Printf("According to Chi Tau records, %v %v has given %v of their %v pledge leaving a delta of %.2f to be recorded in Donately.\n", person.FirstName, person.LastName, c.AmountDonated, c.AmountPledged, delta)
+			logger.InfoContext(ctx, "donately pledge progress", "person_id", person.ID, "first_name", person.FirstName, "last_name", person.LastName, "donated", cumulativeDonation, "pledged", reported.AmountPledged, "due", reported.AmountDue)
+
+			delta := reported.AmountDonated.Sub(cumulativeDonation)
+
+			if delta.Cmp(minimumUnrecordedGift) >= 0 {
+				logger.InfoContext(ctx, "collection report shows unrecorded giving", "person_id", person.ID, "first_name", person.FirstName, "last_name", person.LastName, "donated", reported.AmountDonated, "pledged", reported.AmountPledged, "delta", delta)
 
 				donationToSave := donately.Donation{
-					Account:       account,
-					Person:        person,
-					Campaign:      campaign,
-					DonationType:  "cash",
-					Status:        "processed",
-					AmountInCents: int64(delta * 100),
+					Account:      account,
+					Person:       person,
+					Campaign:     campaign,
+					DonationType: donately.DonationTypeCash,
+					Status:       donately.DonationProcessed,
+				}.WithAmount(delta)
+
+				key := donately.DonationIdempotencyKey(donationToSave, c.SourceRow())
+				donationToSave = donationToSave.WithIdempotencyKey(key)
+
+				entry := donately.LedgerEntry{
+					IdempotencyKey: key,
+					PersonID:       person.ID,
+					CampaignID:     campaign.ID,
+					AmountInCents:  donationToSave.AmountInCents,
+					SourceRow:      c.SourceRow(),
 				}
 
-				fmt.Println("##############################################################################")
-				fmt.Printf("Saving the following donation: person_id: %v, donation_type: %v, amount_in_cents: %v (%v, %v) \n", person.ID, donationToSave.DonationType, donationToSave.AmountInCents, person.FirstName, person.LastName)
-				fmt.Println("##############################################################################")
+				recorded, err := alreadyRecorded(ctx, ledger, donations, entry)
+				if err != nil {
+					return err
+				}
 
-				savedDonation, err := client.SaveDonation(donationToSave)
+				if recorded {
+					logger.InfoContext(ctx, "donation already recorded, skipping", "person_id", person.ID, "idempotency_key", key)
+					return nil
+				}
+
+				if _, err := ledger.BeginLedgerEntry(ctx, entry); err != nil {
+					return err
+				}
+
+				logger.InfoContext(ctx, "saving donation", "person_id", person.ID, "donation_type", donationToSave.DonationType, "amount_in_cents", donationToSave.AmountInCents, "idempotency_key", key)
+
+				savedDonation, err := client.SaveDonation(ctx, donationToSave)
 				if err != nil {
-					recordsByFailureReason[err.Error()] = append(recordsByFailureReason[err.Error()], c)
-					continue
+					// Only forget the attempt when Donately definitely turned it
+					// down; otherwise the next run checks for it before retrying.
+					if errors.Is(err, donatelyhttp.ErrValidation) || errors.Is(err, donatelyhttp.ErrNotFound) || errors.Is(err, donatelyhttp.ErrUnauthorized) {
+						if err := ledger.AbandonLedgerEntry(ctx, key); err != nil {
+							logger.WarnContext(ctx, "encountered an error abandoning a ledger entry", "idempotency_key", key, "error", err)
+						}
+					}
+
+					if errors.Is(err, donatelyhttp.ErrUnauthorized) {
+						return err
+					}
+
+					reason := donatelyhttp.FailureReason(err)
+					recordsByFailureReason[reason] = append(recordsByFailureReason[reason], c)
+					backfillFailures.WithLabelValues(donatelyhttp.FailureClass(err)).Inc()
+					return nil
+				}
+
+				backfillDonationsCreated.Inc()
+
+				if err := ledger.CommitLedgerEntry(ctx, key, savedDonation.ID); err != nil {
+					return err
 				}
 
-				fmt.Printf("%v %v $%v donation saved (donationId=%v)\n", c.FirstName, c.LastName, delta, savedDonation.ID)
+				logger.InfoContext(ctx, "donation saved", "first_name", c.FirstName, "last_name", c.LastName, "amount", delta, "donation_id", savedDonation.ID)
 			}
 		}
+
+		return nil
+	}
+
+	for _, c := range collectionRecords {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		recordCtx, span := tracer.Start(ctx, "backfill record", trace.WithAttributes(
+			attribute.String("donor.source_row_hash", c.SourceRowHash()),
+		))
+
+		err := syncRecord(recordCtx, c)
+		endSpan(span, err)
+
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(recordsByFailureReason) > 0 {
-		fmt.Println("The following Persons couldn't be saved or couldn't have their donation records recorded for one reason or another:")
+		fmt.Fprintln(env.Stdout, "The following Persons couldn't be saved or couldn't have their donation records recorded for one reason or another:")
 
 		for reason, records := range recordsByFailureReason {
-			fmt.Printf("Reason: %v\n", reason)
+			fmt.Fprintf(env.Stdout, "Reason: %v\n", reason)
 
 			for _, record := range records {
-				fmt.Println("##############################################################################")
-				fmt.Printf("Record: %+v\n", record)
-				fmt.Println("##############################################################################")
+				fmt.Fprintln(env.Stdout, "##############################################################################")
+				fmt.Fprintf(env.Stdout, "Record: %+v\n", record)
+				fmt.Fprintln(env.Stdout, "##############################################################################")
 			}
 
-			fmt.Println()
-			fmt.Println()
-			fmt.Println()
+			fmt.Fprintln(env.Stdout)
+			fmt.Fprintln(env.Stdout)
+			fmt.Fprintln(env.Stdout)
 		}
 	}
 
 	return nil
 }
 
+// alreadyRecorded reports whether the donation identified by key was created
+// by an earlier run, either according to the ledger or to the tracking codes
+// on the donations Donately already has.
+func alreadyRecorded(ctx context.Context, ledger donately.DonationLedger, donations []donately.Donation, entry donately.LedgerEntry) (bool, error) {
+	for _, donation := range donations {
+		if donation.IdempotencyKey() == entry.IdempotencyKey {
+			if _, err := ledger.BeginLedgerEntry(ctx, entry); err != nil {
+				return true, err
+			}
+
+			return true, ledger.CommitLedgerEntry(ctx, entry.IdempotencyKey, donation.ID)
+		}
+	}
+
+	recorded, found, err := ledger.FindLedgerEntry(ctx, entry.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+
+	return found && recorded.Status == donately.LedgerCommitted, nil
+}
+
 type ServeCmd struct {
-	AccountID  string `required help:"the account id that this service should leverage."`
-	CampaignID string `required help:"the campaign id that this service should leverage"`
+	AccountID  string `required:"" aliases:"account" help:"the account (id, subdomain or title) that this service should leverage."`
+	CampaignID string `required:"" help:"the campaign id that this service should leverage"`
+
+	CacheTTL time.Duration `name:"cache-ttl" default:"5m" help:"how long Donately reads are cached between dashboard loads (0 disables the cache)."`
+	CacheDB  string        `name:"cache-db" type:"path" help:"persist the Donately cache to this SQLite file instead of memory."`
+
+	WebhookSecret string `name:"webhook-secret" env:"DONATELY_WEBHOOK_SECRET" help:"enables POST /api/webhooks/donately, verifying deliveries signed with this secret."`
+
+	CassetteFlags
 }
 
-func (cmd *ServeCmd) Run(env *Environment, client donatelyhttp.Client, adjustmentStore donately.AdjustmentStore) error {
-	account, err := client.FindAccount(cmd.AccountID)
+func (cmd *ServeCmd) Run(ctx context.Context, env *Environment, logger *slog.Logger, client donatelyhttp.Client, adjustmentStore donately.AdjustmentStore, rates donately.ExchangeRates) error {
+	// Webhook deliveries only invalidate cached reads, so without the cache
+	// they would be acknowledged and then dropped.
+	if cmd.WebhookSecret != "" && cmd.CacheTTL <= 0 {
+		return errors.New("--webhook-secret requires the cache; set --cache-ttl above 0")
+	}
+
+	if cmd.CacheTTL > 0 {
+		store := donately.NewMemoryCacheStore()
+
+		if cmd.CacheDB != "" {
+			var err error
+
+			store, err = donately.NewSQLiteCacheStore(ctx, cmd.CacheDB)
+			if err != nil {
+				return err
+			}
+		}
+
+		client = donatelyhttp.NewCachingClient(client, store, cmd.CacheTTL, logger)
+	}
+
+	account, err := donatelyhttp.ResolveAccount(ctx, client, cmd.AccountID)
 	if err != nil {
-		panic(err.Error())
+		return fmt.Errorf("failed to resolve account %q: %w", cmd.AccountID, err)
 	}
 
-	campaign, err := client.FindCampaign(cmd.CampaignID, account)
+	campaign, err := client.FindCampaign(ctx, cmd.CampaignID, account)
 	if err != nil {
-		panic(err.Error())
+		return fmt.Errorf("failed to find campaign %q: %w", cmd.CampaignID, err)
 	}
 
 	in, err := env.Files.Open("static/inputs/records.csv")
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open the collection report: %w", err)
 	}
 
 	collectionRecords, err := donately.ParseCollectionReportCSV(in)
 
 	if err != nil {
-		panic(err)
+		logger.ErrorContext(ctx, "encountered an error reading the collection report", "error", err)
+		return err
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(otelgin.Middleware(serviceName), donatelyhttp.RequestLogger(logger), donatelyhttp.RequestMetrics(), gin.Recovery())
+
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	api := r.Group("/api")
 	{
@@ -258,12 +433,16 @@ func (cmd *ServeCmd) Run(env *Environment, client donatelyhttp.Client, adjustmen
 			c.JSON(http.StatusOK, gin.H{"status": "ok"})
 		})
 
-		api.GET("/campaign/overview", donatelyhttp.CampaignOverviewHandler(client, adjustmentStore, account, campaign, collectionRecords))
+		api.GET("/campaign/overview", donatelyhttp.CampaignOverviewHandler(client, adjustmentStore, account, campaign, collectionRecords, rates))
+
+		if cmd.WebhookSecret != "" {
+			api.POST("/webhooks/donately", donatelyhttp.WebhookHandler(client, cmd.WebhookSecret, logger))
+		}
 	}
 
 	uiFS, err := fs.Sub(env.UI, "static/donor-dashboard/dist")
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to load the dashboard: %w", err)
 	}
 
 	r.NoRoute(gin.WrapH(http.FileServer(http.FS(uiFS))))
@@ -276,64 +455,282 @@ func (cmd *ServeCmd) Run(env *Environment, client donatelyhttp.Client, adjustmen
 	srv := &http.Server{
 		Addr:    ":" + port,
 		Handler: r,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
 	}
 
+	errs := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen error: %s\n", err)
-		}
+		errs <- srv.ListenAndServe()
 	}()
-	log.Printf("Server running on :%v\n", port)
+	logger.Info("server running", "port", port)
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve on port %s: %w", port, err)
+	case <-ctx.Done():
+	}
 
-	log.Println("Shutting down...")
+	logger.Info("shutting down")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %s", err)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("server forced to shutdown", "error", err)
+		return err
 	}
 
-	log.Println("Server exited gracefully")
+	logger.Info("server exited gracefully")
 
 	return nil
 }
 
-type CLI struct {
-	Backfill BackfillCmd `cmd help:"Backfills Donately donors based on a given account_id and csv file of donor data."`
-	Serve    ServeCmd    `cmd help:"Serves our campaign progress service/ui for visualizing how brothers have progressed on their pledges."`
+type AccountsCmd struct {
+	CassetteFlags
 }
 
-func Run(env Environment) int {
-	app := CLI{}
-
-	client, err := donatelyhttp.NewDonatelyClient()
+func (cmd *AccountsCmd) Run(ctx context.Context, env *Environment, client donatelyhttp.Client) error {
+	accounts, err := client.MyAccounts(ctx)
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
 
-	adjustmentStore, err := donately.NewAdjustmentStore()
-	if err != nil {
-		panic(err.Error())
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tSUBDOMAIN\tTITLE\tCURRENCY")
+	for _, account := range accounts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", account.ID, account.Subdomain, account.Title, account.Currency)
+	}
+
+	return w.Flush()
+}
+
+type FakeServerCmd struct {
+	Seed string `type:"existingfile" help:"a JSON file (accounts, people, donations, subscriptions, campaigns) to seed the fake API with."`
+	Addr string `default:"127.0.0.1:8081" help:"the address the fake API listens on."`
+}
+
+func (cmd *FakeServerCmd) Run(ctx context.Context, logger *slog.Logger) error {
+	var seed fake.Seed
+
+	if cmd.Seed != "" {
+		in, err := os.Open(cmd.Seed)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		seed, err = fake.LoadSeed(in)
+		if err != nil {
+			return err
+		}
 	}
 
+	srv := &http.Server{
+		Addr:    cmd.Addr,
+		Handler: fake.New(seed),
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	logger.Info("fake Donately API running", "base_url", "http://"+cmd.Addr, "accounts", len(seed.Accounts), "people", len(seed.People), "donations", len(seed.Donations))
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+type CLI struct {
+	APIKey      string        `name:"api-key" env:"DONATELY_API_KEY" help:"the Donately API key to authenticate with."`
+	BaseURL     string        `name:"base-url" env:"DONATELY_BASE_URL" default:"${default_base_url}" help:"the Donately API base URL (point this at a local stand-in server for development)."`
+	APIVersion  string        `name:"api-version" env:"DONATELY_API_VERSION" default:"${default_api_version}" help:"the Donately-Version header to send."`
+	Timeout     time.Duration `name:"timeout" default:"30s" help:"per-request timeout for Donately API calls."`
+	Concurrency int           `name:"concurrency" default:"4" help:"how many Donately pages or lookups to fetch at once (requests still share the rate limit)."`
+	LogLevel    string        `name:"log-level" enum:"debug,info,warn,error" default:"info" help:"minimum level to log (debug, info, warn, error)."`
+	LogFormat   string        `name:"log-format" enum:"text,json" default:"text" help:"log output format (text or json)."`
+	Trace       string        `name:"trace" help:"write OpenTelemetry spans as JSON lines to this file (\"-\" for stderr, alongside the logs)."`
+
+	ExchangeRates string `name:"exchange-rates" type:"existingfile" env:"DONATELY_EXCHANGE_RATES" help:"a JSON rate table ({\"base\": \"usd\", \"rates\": {\"cad\": \"0.73\"}}) for totaling gifts and pledges made in other currencies."`
+
+	Backfill   BackfillCmd   `cmd:"" help:"Backfills Donately donors based on a given account_id and csv file of donor data."`
+	Serve      ServeCmd      `cmd:"" help:"Serves our campaign progress service/ui for visualizing how brothers have progressed on their pledges."`
+	Accounts   AccountsCmd   `cmd:"" help:"Lists the Donately accounts this API key can act on."`
+	FakeServer FakeServerCmd `cmd:"" name:"fake-server" help:"Runs an in-memory stand-in for the Donately API for local development."`
+}
+
+func Run(env Environment) int {
+	app := CLI{}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	cntx := kong.Parse(&app,
 		kong.Description("donately utils"),
 		kong.UsageOnError(),
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact: true,
 		}),
+		kong.Vars{
+			"default_base_url":    donatelyhttp.DefaultBaseURL,
+			"default_api_version": donatelyhttp.DefaultAPIVersion,
+		},
 	)
 
-	cntx.BindTo(client, (*donatelyhttp.Client)(nil))
-	cntx.BindTo(adjustmentStore, (*donately.AdjustmentStore)(nil))
+	logger := newLogger(env.Stderr, app.LogLevel, app.LogFormat)
+	slog.SetDefault(logger)
+
+	if app.Trace != "" {
+		shutdownTracing, err := setupTracing(app.Trace, env.Stderr)
+		if err != nil {
+			return fail(cntx, fmt.Errorf("failed to set up tracing: %w", err))
+		}
+
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				logger.Error("failed to flush traces", "error", err)
+			}
+		}()
+	}
+
+	opts := []donatelyhttp.Option{
+		donatelyhttp.WithLogger(logger),
+		donatelyhttp.WithAPIKey(app.APIKey),
+		donatelyhttp.WithBaseURL(app.BaseURL),
+		donatelyhttp.WithAPIVersion(app.APIVersion),
+		donatelyhttp.WithTimeout(app.Timeout),
+		donatelyhttp.WithConcurrency(app.Concurrency),
+	}
+
+	cassetteOpts, closeCassette, err := cassetteOptions(cntx, logger)
+	if err != nil {
+		return fail(cntx, fmt.Errorf("failed to open the cassette: %w", err))
+	}
+	defer closeCassette()
 
-	err = cntx.Run(&env)
-	cntx.FatalIfErrorf(err)
+	cntx.BindTo(ctx, (*context.Context)(nil))
+	cntx.Bind(logger)
+
+	// Only commands that talk to Donately or the database need credentials for
+	// them, so both are built lazily on first use.
+	err = cntx.BindSingletonProvider(func() (donatelyhttp.Client, error) {
+		return donatelyhttp.NewDonatelyClient(append(opts, cassetteOpts...)...)
+	})
+	if err != nil {
+		return fail(cntx, err)
+	}
+
+	err = cntx.BindSingletonProvider(func() (donately.AdjustmentStore, error) {
+		// A replay mustn't write to the real database.
+		if selectedCassette(cntx).Replay != "" {
+			logger.Info("replaying, keeping donor adjustments in memory")
+			return donately.NewMemoryAdjustmentStore(), nil
+		}
+
+		return donately.NewAdjustmentStore()
+	})
+	if err != nil {
+		return fail(cntx, err)
+	}
+
+	err = cntx.BindSingletonProvider(func() (donately.ExchangeRates, error) {
+		if app.ExchangeRates == "" {
+			return donately.ExchangeRates{}, nil
+		}
+
+		return donately.LoadExchangeRates(app.ExchangeRates)
+	})
+	if err != nil {
+		return fail(cntx, err)
+	}
+
+	if err := cntx.Run(&env); err != nil {
+		return fail(cntx, err)
+	}
 
 	return 0
 }
+
+// fail reports err the way kong would and returns the exit code for it. Run
+// returns that code rather than exiting itself so its deferred cleanup (flushing
+// traces, closing the cassette) still happens on failure.
+func fail(cntx *kong.Context, err error) int {
+	cntx.Errorf("%s", err)
+
+	var coder kong.ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	return 1
+}
+
+// selectedCassette returns the --record/--replay flags of the selected
+// command, which are empty for commands that don't take them.
+func selectedCassette(cntx *kong.Context) CassetteFlags {
+	selected := cntx.Selected()
+	if selected == nil {
+		return CassetteFlags{}
+	}
+
+	cmd, ok := selected.Target.Addr().Interface().(interface{ cassette() CassetteFlags })
+	if !ok {
+		return CassetteFlags{}
+	}
+
+	return cmd.cassette()
+}
+
+// cassetteOptions wires up --record/--replay for the selected command.
+func cassetteOptions(cntx *kong.Context, logger *slog.Logger) ([]donatelyhttp.Option, func(), error) {
+	noop := func() {}
+
+	flags := selectedCassette(cntx)
+
+	switch {
+	case flags.Record != "":
+		recorder, err := donatelyhttp.NewRecordingTransport(flags.Record, http.DefaultTransport)
+		if err != nil {
+			return nil, noop, err
+		}
+
+		logger.Info("recording Donately traffic", "cassette", flags.Record)
+
+		closeRecorder := func() {
+			if err := recorder.Close(); err != nil {
+				logger.Error("failed to close cassette", "cassette", flags.Record, "error", err)
+			}
+		}
+
+		return []donatelyhttp.Option{donatelyhttp.WithTransport(recorder)}, closeRecorder, nil
+	case flags.Replay != "":
+		replayer, err := donatelyhttp.NewReplayTransport(flags.Replay)
+		if err != nil {
+			return nil, noop, err
+		}
+
+		logger.Info("replaying Donately traffic", "cassette", flags.Replay)
+
+		return []donatelyhttp.Option{
+			donatelyhttp.WithTransport(replayer),
+			donatelyhttp.WithRateLimit(donatelyhttp.RateLimit{}),
+			donatelyhttp.WithReplayAPIKey(),
+		}, noop, nil
+	}
+
+	return nil, noop, nil
+}
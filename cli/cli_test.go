@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+	donatelyhttp "github.com/willmadison/donately-sync-tools/donately/http"
+	"github.com/willmadison/donately-sync-tools/donately/http/fake"
+)
+
+const testAPIKey = "test-key"
+
+var (
+	testAccount  = donately.Account{ID: "act_test", Title: "Test Chapter", Subdomain: "test", Currency: "usd"}
+	testCampaign = donately.Campaign{ID: "cmp_test", Title: "Capital Campaign", Account: testAccount, GoalInCents: 100000}
+	testDonor    = donately.Person{ID: "person_grace", Email: "grace@example.com", FirstName: "Grace", LastName: "Hopper", Accounts: []donately.Account{testAccount}}
+)
+
+const testReport = `First Name,Last Name,Email Address,Amount Donated,Current Amount Due,Amount Pledged
+Grace,Hopper,grace@example.com,100,900,1000
+`
+
+func testSeed() fake.Seed {
+	return fake.Seed{
+		APIKey:    testAPIKey,
+		Accounts:  []donately.Account{testAccount},
+		Campaigns: []donately.Campaign{testCampaign},
+		People:    []donately.Person{testDonor},
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func testLedger(t *testing.T, path string) donately.DonationLedger {
+	t.Helper()
+
+	ledger, err := donately.NewDonationLedger(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+
+	return ledger
+}
+
+// backfill runs BackfillCmd over report against handler, recording to the
+// ledger at ledgerPath.
+func backfill(t *testing.T, handler http.Handler, report, ledgerPath string) string {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	client, err := donatelyhttp.NewDonatelyClient(
+		donatelyhttp.WithBaseURL(srv.URL),
+		donatelyhttp.WithAPIKey(testAPIKey),
+		donatelyhttp.WithLogger(discardLogger()),
+		donatelyhttp.WithRateLimit(donatelyhttp.RateLimit{}),
+		donatelyhttp.WithRetryPolicy(donatelyhttp.RetryPolicy{MaxAttempts: 1, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout strings.Builder
+
+	env := &Environment{
+		Stdout: &stdout,
+		Stderr: io.Discard,
+		Files:  fstest.MapFS{"static/inputs/records.csv": {Data: []byte(report)}},
+	}
+
+	cmd := BackfillCmd{AccountID: testAccount.ID, CampaignID: testCampaign.ID, Ledger: ledgerPath}
+
+	if err := cmd.Run(context.Background(), env, discardLogger(), client, donately.NewMemoryAdjustmentStore(), donately.ExchangeRates{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return stdout.String()
+}
+
+// testDonationKey is the idempotency key backfill gives Grace's missing $100.
+func testDonationKey() string {
+	donation := donately.Donation{Person: testDonor, Campaign: testCampaign, AmountInCents: 10000}
+	return donately.DonationIdempotencyKey(donation, "grace@example.com|100.00")
+}
+
+func TestAlreadyRecorded(t *testing.T) {
+	entry := donately.LedgerEntry{IdempotencyKey: "dst_1", PersonID: "person_1", CampaignID: "cmp_1", AmountInCents: 2500}
+
+	tests := []struct {
+		name       string
+		ledger     donately.LedgerStatus // the entry's status before the check, if any
+		donations  []donately.Donation
+		want       bool
+		wantStatus donately.LedgerStatus // the entry's status after the check, if any
+		wantID     string
+	}{
+		{name: "never attempted"},
+		{
+			name:       "pending, not found in Donately",
+			ledger:     donately.LedgerPending,
+			wantStatus: donately.LedgerPending,
+		},
+		{
+			name:       "committed",
+			ledger:     donately.LedgerCommitted,
+			want:       true,
+			wantStatus: donately.LedgerCommitted,
+			wantID:     "donation_1",
+		},
+		{
+			name:       "pending, found by its tracking code",
+			ledger:     donately.LedgerPending,
+			donations:  []donately.Donation{{ID: "donation_2", TrackingCodes: "spring-drive,dst_1"}},
+			want:       true,
+			wantStatus: donately.LedgerCommitted,
+			wantID:     "donation_2",
+		},
+		{
+			name:       "missing from the ledger, found by its tracking code",
+			donations:  []donately.Donation{{ID: "donation_3", TrackingCodes: "dst_1"}},
+			want:       true,
+			wantStatus: donately.LedgerCommitted,
+			wantID:     "donation_3",
+		},
+		{
+			name:      "other donations' keys don't count",
+			donations: []donately.Donation{{ID: "donation_4", TrackingCodes: "dst_2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ledger := testLedger(t, filepath.Join(t.TempDir(), "ledger.db"))
+
+			if tt.ledger != "" {
+				if _, err := ledger.BeginLedgerEntry(ctx, entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.ledger == donately.LedgerCommitted {
+				if err := ledger.CommitLedgerEntry(ctx, entry.IdempotencyKey, "donation_1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			recorded, err := alreadyRecorded(ctx, ledger, tt.donations, entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if recorded != tt.want {
+				t.Errorf("alreadyRecorded = %v, want %v", recorded, tt.want)
+			}
+
+			found, ok, err := ledger.FindLedgerEntry(ctx, entry.IdempotencyKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok != (tt.wantStatus != "") || found.Status != tt.wantStatus || found.DonationID != tt.wantID {
+				t.Errorf("ledger entry = %+v (found %v), want status %q for %q", found, ok, tt.wantStatus, tt.wantID)
+			}
+		})
+	}
+}
+
+// TestBackfillLedgerWhenSaveFails checks that a donation Donately turned down
+// is forgotten, so the next run can try again, while one that may have gone
+// through is left pending for the next run to look for first.
+func TestBackfillLedgerWhenSaveFails(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus donately.LedgerStatus
+	}{
+		{name: "validation error", status: http.StatusUnprocessableEntity},
+		{name: "server error", status: http.StatusInternalServerError, wantStatus: donately.LedgerPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fake.New(testSeed())
+
+			failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/donations") {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(tt.status)
+					io.WriteString(w, `{"type":"invalid_request_error","message":"declined"}`)
+					return
+				}
+
+				server.ServeHTTP(w, r)
+			})
+
+			ledgerPath := filepath.Join(t.TempDir(), "ledger.db")
+
+			if out := backfill(t, failing, testReport, ledgerPath); !strings.Contains(out, "Grace") {
+				t.Errorf("output = %q, want Grace reported as not saved", out)
+			}
+
+			if donations := server.Snapshot().Donations; len(donations) != 0 {
+				t.Fatalf("created %d donations, want none", len(donations))
+			}
+
+			entry, found, err := testLedger(t, ledgerPath).FindLedgerEntry(context.Background(), testDonationKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if found != (tt.wantStatus != "") || entry.Status != tt.wantStatus {
+				t.Errorf("ledger entry = %+v (found %v), want status %q", entry, found, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	alan := donately.Person{ID: "person_alan", Email: "alan@example.com", FirstName: "Alan", LastName: "Turing", Accounts: []donately.Account{testAccount}}
+	fundraiser := donately.Fundraiser{ID: "fundraiser_grace", Title: "Grace's page", Person: testDonor, Campaign: testCampaign, Account: testAccount}
+
+	seed := testSeed()
+	seed.People = append(seed.People, alan)
+	seed.Fundraisers = []donately.Fundraiser{fundraiser}
+	// Alan gave through Grace's fundraiser, which counts toward Alan's pledge,
+	// not Grace's.
+	seed.Donations = []donately.Donation{{
+		ID:            "donation_alan",
+		Account:       testAccount,
+		Campaign:      testCampaign,
+		Person:        alan,
+		Fundraiser:    &fundraiser,
+		AmountInCents: 50000,
+		Currency:      "usd",
+		DonationType:  donately.DonationTypeCash,
+		Status:        donately.DonationProcessed,
+		Livemode:      true,
+	}}
+
+	report := testReport +
+		"Alan,Turing,alan@example.com,500,500,1000\n" +
+		"Ada,Lovelace,ada@example.com,\"1,000.50\",0,\"1,000.50\"\n"
+
+	server := fake.New(seed)
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	if out := backfill(t, server, report, ledgerPath); out != "" {
+		t.Errorf("output = %q, want no failures reported", out)
+	}
+
+	after := server.Snapshot()
+
+	var ada []donately.Person
+	for _, person := range after.People {
+		if person.Email == "ada@example.com" {
+			ada = append(ada, person)
+		}
+	}
+
+	if len(after.People) != 3 || len(ada) != 1 {
+		t.Errorf("got %d people, %d of them Ada, want Ada added to Grace and Alan", len(after.People), len(ada))
+	}
+
+	if len(after.Donations) != 2 {
+		t.Fatalf("got %d donations, want one added for Grace", len(after.Donations))
+	}
+
+	created := after.Donations[1]
+
+	if created.Person.ID != testDonor.ID || created.Campaign.ID != testCampaign.ID || created.AmountInCents != 10000 || created.DonationType != donately.DonationTypeCash {
+		t.Errorf("created a %d cent %s donation from %s to %s, want $100.00 cash from Grace to the campaign", created.AmountInCents, created.DonationType, created.Person.ID, created.Campaign.ID)
+	}
+
+	if created.IdempotencyKey() != testDonationKey() {
+		t.Errorf("created donation's tracking codes = %q, want them to carry %s", created.TrackingCodes, testDonationKey())
+	}
+
+	entry, found, err := testLedger(t, ledgerPath).FindLedgerEntry(context.Background(), testDonationKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !found || entry.Status != donately.LedgerCommitted || entry.DonationID != created.ID {
+		t.Errorf("ledger entry = %+v (found %v), want it committed to %s", entry, found, created.ID)
+	}
+
+	// Everything the report shows is in Donately now, so another run has
+	// nothing left to create.
+	if out := backfill(t, server, report, ledgerPath); out != "" {
+		t.Errorf("second run output = %q, want no failures reported", out)
+	}
+
+	rerun := server.Snapshot()
+
+	if len(rerun.People) != len(after.People) || len(rerun.Donations) != len(after.Donations) {
+		t.Errorf("second run left %d people and %d donations, want %d and %d", len(rerun.People), len(rerun.Donations), len(after.People), len(after.Donations))
+	}
+}
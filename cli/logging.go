@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	donatelyhttp "github.com/willmadison/donately-sync-tools/donately/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newLogger(w io.Writer, level, format string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(spanEventHandler{handler})
+}
+
+// spanEventHandler also records each log line as an event on the span in its
+// context, so a trace shows the decisions made alongside the calls. Trace files
+// outlive the terminal, so donor names and other PII are masked in the events
+// just as they are in request logs.
+type spanEventHandler struct {
+	slog.Handler
+}
+
+func (h spanEventHandler) Handle(ctx context.Context, r slog.Record) error {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		attrs := make([]attribute.KeyValue, 0, r.NumAttrs()+1)
+		attrs = append(attrs, attribute.String("log.severity", r.Level.String()))
+
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, attribute.String(a.Key, donatelyhttp.RedactField(a.Key, a.Value.String())))
+			return true
+		})
+
+		span.AddEvent(r.Message, trace.WithAttributes(attrs...))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h spanEventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return spanEventHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h spanEventHandler) WithGroup(name string) slog.Handler {
+	return spanEventHandler{h.Handler.WithGroup(name)}
+}
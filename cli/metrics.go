@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	backfillPersonsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "persons_created_total",
+		Help:      "People added to Donately by backfill.",
+	})
+
+	backfillDonationsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "donations_created_total",
+		Help:      "Donations recorded in Donately by backfill.",
+	})
+
+	backfillAdjustmentsUpdated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "adjustments_updated_total",
+		Help:      "Donors whose adjustments backfill replaced from the collection report.",
+	})
+
+	backfillFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "failures_total",
+		Help:      "Collection report records backfill could not sync, by failure class (not_found, validation, rate_limited, unauthorized or other).",
+	}, []string{"reason"})
+
+	backfillLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "When the last backfill finished.",
+	})
+
+	backfillLastRunSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "donately",
+		Subsystem: "backfill",
+		Name:      "last_run_success",
+		Help:      "1 if the last backfill finished without failures, 0 otherwise.",
+	})
+)
+
+func recordBackfillRun(failed bool) {
+	backfillLastRun.Set(float64(time.Now().Unix()))
+
+	if failed {
+		backfillLastRunSuccess.Set(0)
+	} else {
+		backfillLastRunSuccess.Set(1)
+	}
+}
+
+// writeMetricsTextfile saves every registered metric to path in the format
+// node_exporter's textfile collector reads, since a backfill exits long before
+// anything could scrape it.
+func writeMetricsTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, prometheus.DefaultGatherer)
+}
@@ -22,13 +22,16 @@ interface CampaignOverview {
     amount_raised_in_cents: number;
     percent_funded: number;
     donors: Donor[];
+    fundraisers: Fundraiser[];
 }
 
 interface Donor {
     person: Person;
     pledge: number;
+    donated: number;
     donations: Donation[];
     adjustments: Adjustment[];
+    fundraiser?: Fundraiser;
 }
 
 export interface Person {
@@ -42,6 +45,13 @@ export interface Donation {
     amount_in_cents: number;
 }
 
+export interface Fundraiser {
+    id: string;
+    title: string;
+    goal_in_cents: number;
+    amount_raised_in_cents: number;
+}
+
 export interface Adjustment {
     name: string;
     slug: string;
@@ -102,7 +112,7 @@ export default function DonorDashboard() {
             <h2 className="text-xl font-semibold">Donor Progress</h2>
             <div className="space-y-4 max-h-[400px] overflow-y-auto pr-2">
                 {campaignOverview.donors.map((donor, index) => {
-                    const totalDonations = donor.donations.reduce((sum, donation) => sum + (donation.amount_in_cents / 100), 0);
+                    const totalDonations = donor.donated;
                     const totalAdjustments = donor.adjustments.reduce((sum, adjustment) => sum + adjustment.amount, 0);
                     const goalMet = totalDonations + totalAdjustments >= donor.pledge;
                     const progress = Math.min(((totalDonations + totalAdjustments) / donor.pledge) * 100, 100);
@@ -123,6 +133,12 @@ export default function DonorDashboard() {
                                     <span>{Math.round(progress)}%</span>
                                 </div>
                                 <Progress value={progress} />
+                                {donor.fundraiser && (
+                                    <div className="flex justify-between text-xs text-muted-foreground">
+                                        <span>{donor.fundraiser.title}</span>
+                                        <span>${(donor.fundraiser.amount_raised_in_cents / 100).toFixed(2)} / ${(donor.fundraiser.goal_in_cents / 100).toFixed(2)} raised</span>
+                                    </div>
+                                )}
                             </CardContent>
                         </Card>
                     );
@@ -7,8 +7,9 @@ type Account struct {
 	DonatelyHomepageURL     string            `json:"donately_homepage_url"`
 	Status                  string            `json:"status"`
 	Currency                string            `json:"currency"`
-	Created                 int64             `json:"created"`
-	Updated                 int64             `json:"updated"`
+	Timezone                string            `json:"timezone"`
+	Created                 UnixTime          `json:"created"`
+	Updated                 UnixTime          `json:"updated"`
 	TaxID                   *string           `json:"tax_id"`
 	TaxExemptStatus         *string           `json:"tax_exempt_status"`
 	DBAName                 *string           `json:"dba_name"`
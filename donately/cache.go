@@ -0,0 +1,119 @@
+package donately
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately/internal/sqlite"
+	"github.com/willmadison/donately-sync-tools/donately/internal/sqlite/donors"
+)
+
+// CacheStore holds encoded API responses for a caching client. Expiry is up to
+// the caller, which is handed back the time each entry was stored.
+type CacheStore interface {
+	GetCacheEntry(ctx context.Context, key string) ([]byte, time.Time, bool, error)
+	SaveCacheEntry(ctx context.Context, key string, value []byte) error
+	DeleteCacheEntries(ctx context.Context, prefix string) error
+}
+
+type cacheEntry struct {
+	value    []byte
+	storedAt time.Time
+}
+
+type memoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCacheStore returns a CacheStore that lives as long as the process.
+func NewMemoryCacheStore() CacheStore {
+	return &memoryCacheStore{entries: map[string]cacheEntry{}}
+}
+
+func (m *memoryCacheStore) GetCacheEntry(_ context.Context, key string) ([]byte, time.Time, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	return entry.value, entry.storedAt, ok, nil
+}
+
+func (m *memoryCacheStore) SaveCacheEntry(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = cacheEntry{value: value, storedAt: time.Now()}
+	return nil
+}
+
+func (m *memoryCacheStore) DeleteCacheEntries(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+
+	return nil
+}
+
+type sqliteCacheStore struct {
+	queries *donors.Queries
+}
+
+func (s sqliteCacheStore) GetCacheEntry(ctx context.Context, key string) ([]byte, time.Time, bool, error) {
+	entry, err := s.queries.GetCacheEntry(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("encountered an error reading the cache: %w", err)
+	}
+
+	return entry.Value, time.Unix(entry.StoredAt, 0), true, nil
+}
+
+func (s sqliteCacheStore) SaveCacheEntry(ctx context.Context, key string, value []byte) error {
+	err := s.queries.SaveCacheEntry(ctx, donors.SaveCacheEntryParams{
+		Key:      key,
+		Value:    value,
+		StoredAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("encountered an error writing to the cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s sqliteCacheStore) DeleteCacheEntries(ctx context.Context, prefix string) error {
+	if err := s.queries.DeleteCacheEntriesByPrefix(ctx, prefix); err != nil {
+		return fmt.Errorf("encountered an error invalidating the cache: %w", err)
+	}
+
+	return nil
+}
+
+// NewSQLiteCacheStore opens (creating if needed) a persistent cache at path,
+// so a restarted server doesn't begin cold.
+func NewSQLiteCacheStore(ctx context.Context, path string) (CacheStore, error) {
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return nil, fmt.Errorf("encountered an error opening the cache: %s", err)
+	}
+
+	if err := sqlite.Up(ctx, db, "0003_cache_entries.sql"); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sqliteCacheStore{queries: donors.New(db)}, nil
+}
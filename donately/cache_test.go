@@ -0,0 +1,58 @@
+package donately
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeleteCacheEntriesMatchesPrefixLiterally(t *testing.T) {
+	ctx := context.Background()
+
+	sqliteStore, err := NewSQLiteCacheStore(ctx, filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]CacheStore{
+		"memory": NewMemoryCacheStore(),
+		"sqlite": sqliteStore,
+	}
+
+	// "_" and "%" are LIKE wildcards and LIKE ignores case; neither may widen
+	// the prefix.
+	keys := map[string]bool{
+		"donations:act_local:page": false,
+		"donations:act_local:":     false,
+		"donations:actXlocal:page": true,
+		"donations:ACT_LOCAL:page": true,
+		"donations:act%local:page": true,
+		"donations:act_locals":     true,
+		"campaign:act_local:cmp_1": true,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			for key := range keys {
+				if err := store.SaveCacheEntry(ctx, key, []byte("{}")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := store.DeleteCacheEntries(ctx, "donations:act_local:"); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range keys {
+				_, _, ok, err := store.GetCacheEntry(ctx, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if ok != want {
+					t.Errorf("%s cached = %v, want %v", key, ok, want)
+				}
+			}
+		})
+	}
+}
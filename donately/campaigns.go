@@ -4,32 +4,48 @@ import "time"
 
 type Campaign struct {
 	ID                  string         `json:"id"`
-	Title               string         `json:"title"`
-	Slug                string         `json:"slug"`
-	Type                string         `json:"type"`
+	Title               string         `json:"title" form:"title"`
+	Slug                string         `json:"slug" form:"slug"`
+	Type                string         `json:"type" form:"type"`
 	URL                 string         `json:"url"`
-	Status              string         `json:"status"`
+	Status              string         `json:"status" form:"status"`
 	Permalink           string         `json:"permalink"`
-	Description         *string        `json:"description"`
-	Content             *string        `json:"content"`
-	Created             int64          `json:"created"`
-	Updated             int64          `json:"updated"`
-	StartDate           *string        `json:"start_date"` // use *time.Time if ISO format is confirmed
-	EndDate             *string        `json:"end_date"`
-	GoalInCents         int64          `json:"goal_in_cents"`
+	Description         *string        `json:"description" form:"description"`
+	Content             *string        `json:"content" form:"content"`
+	Created             UnixTime       `json:"created"`
+	Updated             UnixTime       `json:"updated"`
+	StartDate           *Date          `json:"start_date" form:"start_date"`
+	EndDate             *Date          `json:"end_date" form:"end_date"`
+	GoalInCents         int64          `json:"goal_in_cents" form:"goal_in_cents"`
 	AmountRaisedInCents int64          `json:"amount_raised_in_cents"`
 	PercentFunded       float64        `json:"percent_funded"`
 	DonorsCount         int            `json:"donors_count"`
 	Images              CampaignImages `json:"images"`
-	Account             Account        `json:"account"`
+	Account             Account        `json:"account" form:"account_id,always"`
 	FormID              string         `json:"form_id"`
 	MetaData            any            `json:"meta_data"`
 	InternalID          int64          `json:"internal_id"`
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
-	Recurring           *bool          `json:"recurring"`
-	FundraiserGoal      *int64         `json:"fundraiser_goal"`
-	DonationAmount      *int64         `json:"donation_amount"`
+	Recurring           *bool          `json:"recurring" form:"recurring"`
+	FundraiserGoal      *int64         `json:"fundraiser_goal" form:"fundraiser_goal"`
+	DonationAmount      *int64         `json:"donation_amount" form:"donation_amount"`
+}
+
+// Includes reports whether donation was given within the campaign's start and
+// end dates, both inclusive, as days in loc. An unset bound is open.
+func (c Campaign) Includes(donation Donation, loc *time.Location) bool {
+	day := donation.DateIn(loc)
+
+	if c.StartDate != nil && !c.StartDate.IsZero() && day.Before(*c.StartDate) {
+		return false
+	}
+
+	if c.EndDate != nil && !c.EndDate.IsZero() && day.After(*c.EndDate) {
+		return false
+	}
+
+	return true
 }
 
 type CampaignImages struct {
@@ -54,21 +70,22 @@ type CampaignCoverPhotoSizes struct {
 }
 
 type CampaignOverview struct {
-	ID                  string  `json:"id"`
-	Title               string  `json:"title"`
-	Slug                string  `json:"slug"`
-	Type                string  `json:"type"`
-	URL                 string  `json:"url"`
-	Status              string  `json:"status"`
-	Permalink           string  `json:"permalink"`
-	Description         *string `json:"description"`
-	Content             *string `json:"content"`
-	Created             int64   `json:"created"`
-	Updated             int64   `json:"updated"`
-	StartDate           *string `json:"start_date"` // use *time.Time if ISO format is confirmed
-	EndDate             *string `json:"end_date"`
-	GoalInCents         int64   `json:"goal_in_cents"`
-	AmountRaisedInCents int64   `json:"amount_raised_in_cents"`
-	PercentFunded       float64 `json:"percent_funded"`
-	Donors              []Donor `json:"donors"`
+	ID                  string       `json:"id"`
+	Title               string       `json:"title"`
+	Slug                string       `json:"slug"`
+	Type                string       `json:"type"`
+	URL                 string       `json:"url"`
+	Status              string       `json:"status"`
+	Permalink           string       `json:"permalink"`
+	Description         *string      `json:"description"`
+	Content             *string      `json:"content"`
+	Created             UnixTime     `json:"created"`
+	Updated             UnixTime     `json:"updated"`
+	StartDate           *Date        `json:"start_date"`
+	EndDate             *Date        `json:"end_date"`
+	GoalInCents         int64        `json:"goal_in_cents"`
+	AmountRaisedInCents int64        `json:"amount_raised_in_cents"`
+	PercentFunded       float64      `json:"percent_funded"`
+	Donors              []Donor      `json:"donors"`
+	Fundraisers         []Fundraiser `json:"fundraisers"`
 }
@@ -0,0 +1,198 @@
+package donately
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+var ErrNoExchangeRate = errors.New("donately: no exchange rate")
+
+// ExchangeRates is a static conversion table, loaded from a JSON file like
+//
+//	{"base": "usd", "rates": {"cad": "0.73", "eur": "1.08"}}
+//
+// where each rate is what one unit of that currency is worth in base. Rates
+// may be numbers or strings; either way they are read exactly. The zero value
+// converts nothing but amounts already in the target currency.
+type ExchangeRates struct {
+	Base  string
+	rates map[string]*big.Rat
+}
+
+// LoadExchangeRates reads a rate table from path.
+func LoadExchangeRates(path string) (ExchangeRates, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ExchangeRates{}, fmt.Errorf("encountered an error reading exchange rates: %w", err)
+	}
+
+	var table struct {
+		Base  string                     `json:"base"`
+		Rates map[string]json.RawMessage `json:"rates"`
+	}
+
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return ExchangeRates{}, fmt.Errorf("encountered an error parsing exchange rates: %w", err)
+	}
+
+	if table.Base == "" {
+		return ExchangeRates{}, fmt.Errorf("exchange rates in %s are missing a base currency", path)
+	}
+
+	rates := ExchangeRates{Base: strings.ToLower(table.Base), rates: map[string]*big.Rat{}}
+
+	for currency, value := range table.Rates {
+		text := string(bytes.Trim(value, `"`))
+
+		rate, ok := new(big.Rat).SetString(text)
+		if !ok || rate.Sign() <= 0 {
+			return ExchangeRates{}, fmt.Errorf("invalid exchange rate %s for %s", text, currency)
+		}
+
+		rates.rates[strings.ToLower(currency)] = rate
+	}
+
+	return rates, nil
+}
+
+// rate is what one unit of currency is worth in the base currency.
+func (r ExchangeRates) rate(currency string) (*big.Rat, bool) {
+	if currency == r.Base && currency != "" {
+		return big.NewRat(1, 1), true
+	}
+
+	rate, ok := r.rates[currency]
+	return rate, ok
+}
+
+// Convert returns m in currency, rounded to the nearest cent. Amounts without
+// a currency are taken to already be in it.
+func (r ExchangeRates) Convert(m Money, currency string) (Money, error) {
+	currency = strings.ToLower(currency)
+	from := strings.ToLower(m.Currency)
+
+	if from == "" || from == currency {
+		return NewMoney(m.Cents, currency), nil
+	}
+
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return Money{}, fmt.Errorf("%w from %s", ErrNoExchangeRate, from)
+	}
+
+	toRate, ok := r.rate(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w to %s", ErrNoExchangeRate, currency)
+	}
+
+	converted := new(big.Rat).SetInt64(m.Cents)
+	converted.Mul(converted, fromRate)
+	converted.Quo(converted, toRate)
+
+	return NewMoney(roundRat(converted), currency), nil
+}
+
+// roundRat rounds r to the nearest integer, halves away from zero.
+func roundRat(r *big.Rat) int64 {
+	num, den := new(big.Int).Set(r.Num()), r.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if negative {
+		quotient.Neg(quotient)
+	}
+
+	return quotient.Int64()
+}
+
+// ReportingCurrency is the currency the account's totals are kept in.
+func (a Account) ReportingCurrency() string {
+	switch {
+	case a.Currency != "":
+		return strings.ToLower(a.Currency)
+	case a.Processors.DefaultCurrency != "":
+		return strings.ToLower(a.Processors.DefaultCurrency)
+	default:
+		return "usd"
+	}
+}
+
+// NetAmountIn is NetAmount in currency. Gifts Donately already valued in
+// dollars use that valuation, made when the gift was; anything else goes
+// through rates.
+func (d Donation) NetAmountIn(currency string, rates ExchangeRates) (Money, error) {
+	net := d.NetAmount()
+
+	if net.Currency == "" || strings.EqualFold(net.Currency, currency) {
+		return NewMoney(net.Cents, currency), nil
+	}
+
+	if strings.EqualFold(currency, "usd") && d.AmountInCentsUSD > 0 && d.AmountInCents > 0 {
+		usd := new(big.Rat).SetInt64(net.Cents)
+		usd.Mul(usd, big.NewRat(d.AmountInCentsUSD, d.AmountInCents))
+
+		return NewMoney(roundRat(usd), currency), nil
+	}
+
+	return rates.Convert(net, currency)
+}
+
+// NetTotal sums the NetAmountIn currency of donations.
+func NetTotal(donations []Donation, currency string, rates ExchangeRates) (Money, error) {
+	total := NewMoney(0, currency)
+
+	for _, donation := range donations {
+		amount, err := donation.NetAmountIn(currency, rates)
+		if err != nil {
+			return total, fmt.Errorf("donation %s: %w", donation.ID, err)
+		}
+
+		total = total.Add(amount)
+	}
+
+	return total, nil
+}
+
+// In returns the record with its amounts and adjustments converted to
+// currency.
+func (c CollectionReportRecord) In(currency string, rates ExchangeRates) (CollectionReportRecord, error) {
+	var err error
+
+	convert := func(m Money) Money {
+		if err != nil {
+			return m
+		}
+
+		var converted Money
+		converted, err = rates.Convert(m, currency)
+		return converted
+	}
+
+	converted := c
+	converted.AmountDonated = convert(c.AmountDonated)
+	converted.AmountDue = convert(c.AmountDue)
+	converted.AmountPledged = convert(c.AmountPledged)
+	converted.Adjustments = nil
+
+	for _, adjustment := range c.Adjustments {
+		adjustment.Amount = convert(adjustment.Amount)
+		converted.Adjustments = append(converted.Adjustments, adjustment)
+	}
+
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", c.EmailAddress, err)
+	}
+
+	return converted, nil
+}
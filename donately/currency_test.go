@@ -0,0 +1,145 @@
+package donately
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testRates(t *testing.T) ExchangeRates {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"CAD": "0.73", "eur": 1.08, "jpy": "1/150"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := LoadExchangeRates(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rates
+}
+
+func TestLoadExchangeRatesRejectsBadTables(t *testing.T) {
+	for name, table := range map[string]string{
+		"missing base":  `{"rates": {"cad": "0.73"}}`,
+		"zero rate":     `{"base": "usd", "rates": {"cad": "0"}}`,
+		"negative rate": `{"base": "usd", "rates": {"cad": "-0.73"}}`,
+		"not a number":  `{"base": "usd", "rates": {"cad": "lots"}}`,
+		"not json":      `base=usd`,
+	} {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		if err := os.WriteFile(path, []byte(table), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadExchangeRates(path); err == nil {
+			t.Errorf("%s: LoadExchangeRates succeeded, want an error", name)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rates := testRates(t)
+
+	tests := []struct {
+		name     string
+		from     Money
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{name: "same currency", from: NewMoney(1234, "cad"), currency: "CAD", want: NewMoney(1234, "cad")},
+		{name: "no currency takes the target", from: NewMoney(1234, ""), currency: "eur", want: NewMoney(1234, "eur")},
+		{name: "into the base", from: NewMoney(10000, "cad"), currency: "usd", want: NewMoney(7300, "usd")},
+		{name: "out of the base", from: NewMoney(7300, "usd"), currency: "cad", want: NewMoney(10000, "cad")},
+		{name: "between two others", from: NewMoney(10000, "eur"), currency: "cad", want: NewMoney(14795, "cad")},
+		{name: "rounds to the nearest cent", from: NewMoney(1, "cad"), currency: "usd", want: NewMoney(1, "usd")},
+		{name: "rounds negatives away from zero", from: NewMoney(-75, "jpy"), currency: "usd", want: NewMoney(-1, "usd")},
+		{name: "unknown source", from: NewMoney(100, "gbp"), currency: "usd", wantErr: ErrNoExchangeRate},
+		{name: "unknown target", from: NewMoney(100, "usd"), currency: "gbp", wantErr: ErrNoExchangeRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(tt.from, tt.currency)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && got != tt.want {
+				t.Errorf("Convert = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithoutRates(t *testing.T) {
+	var rates ExchangeRates
+
+	if got, err := rates.Convert(NewMoney(500, "usd"), "usd"); err != nil || got != NewMoney(500, "usd") {
+		t.Errorf("Convert(usd to usd) = %#v, %v", got, err)
+	}
+
+	if _, err := rates.Convert(NewMoney(500, "cad"), "usd"); !errors.Is(err, ErrNoExchangeRate) {
+		t.Errorf("Convert(cad to usd) error = %v, want ErrNoExchangeRate", err)
+	}
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     int64
+	}{
+		{num: 0, den: 1, want: 0},
+		{num: 7, den: 1, want: 7},
+		{num: 1, den: 3, want: 0},
+		{num: 2, den: 3, want: 1},
+		{num: 1, den: 2, want: 1},
+		{num: 3, den: 2, want: 2},
+		{num: 5, den: 2, want: 3},
+		{num: -1, den: 2, want: -1},
+		{num: -5, den: 2, want: -3},
+		{num: -1, den: 3, want: 0},
+		{num: 1234499, den: 1000, want: 1234},
+		{num: 1234500, den: 1000, want: 1235},
+	}
+
+	for _, tt := range tests {
+		if got := roundRat(big.NewRat(tt.num, tt.den)); got != tt.want {
+			t.Errorf("roundRat(%d/%d) = %d, want %d", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestNetTotal(t *testing.T) {
+	rates := testRates(t)
+
+	donations := []Donation{
+		{ID: "usd", Livemode: true, Status: DonationProcessed, AmountInCents: 1000, Currency: "usd"},
+		{ID: "cad", Livemode: true, Status: DonationProcessed, AmountInCents: 1000, Currency: "cad"},
+		// Donately's own dollar valuation wins over today's rate.
+		{ID: "eur", Livemode: true, Status: DonationProcessed, AmountInCents: 1000, Currency: "eur", AmountInCentsUSD: 1100},
+		{ID: "pending", Livemode: true, Status: DonationPending, AmountInCents: 1000, Currency: "usd"},
+	}
+
+	total, err := NetTotal(donations, "usd", rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := NewMoney(1000+730+1100, "usd"); total != want {
+		t.Errorf("NetTotal = %#v, want %#v", total, want)
+	}
+
+	donations = append(donations, Donation{ID: "gbp", Livemode: true, Status: DonationProcessed, AmountInCents: 1000, Currency: "gbp"})
+
+	if _, err := NetTotal(donations, "usd", rates); !errors.Is(err, ErrNoExchangeRate) {
+		t.Errorf("NetTotal with a gbp gift error = %v, want ErrNoExchangeRate", err)
+	}
+}
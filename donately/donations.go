@@ -1,53 +1,155 @@
 package donately
 
 import (
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Donation struct {
-	ID                  string       `json:"id"`
-	DonationType        string       `json:"donation_type"`
-	Processor           string       `json:"processor"`
-	Status              string       `json:"status"`
-	Livemode            bool         `json:"livemode"`
-	DonationDate        int64        `json:"donation_date"`
-	AmountInCents       int64        `json:"amount_in_cents"`
-	Currency            string       `json:"currency"`
-	Recurring           bool         `json:"recurring"`
-	Refunded            *bool        `json:"refunded"`
-	TransactionID       string       `json:"transaction_id"`
-	Created             int64        `json:"created"`
-	Updated             int64        `json:"updated"`
-	AmountFormatted     string       `json:"amount_formatted"`
-	Anonymous           bool         `json:"anonymous"`
-	OnBehalfOf          string       `json:"on_behalf_of"`
-	Comment             string       `json:"comment"`
-	TrackingCodes       string       `json:"tracking_codes"`
-	MetaData            MetaData     `json:"meta_data"`
-	Person              Person       `json:"person"`
-	Account             Account      `json:"account"`
-	Campaign            Campaign     `json:"campaign"`
-	Fundraiser          any          `json:"fundraiser"`
-	Subscription        Subscription `json:"subscription"`
-	Parent              any          `json:"parent"`
-	Refunds             []any        `json:"refunds"`
-	ChargeSource        ChargeSource `json:"charge_source"`
-	ReferrerID          *string      `json:"referrer_id"`
-	RemoteIP            string       `json:"remote_ip"`
-	FeeInCents          int64        `json:"fee_in_cents"`
-	InternalID          int64        `json:"internal_id"`
-	CreatedAt           time.Time    `json:"created_at"`
-	UpdatedAt           time.Time    `json:"updated_at"`
-	FeeStripeChargeID   string       `json:"fee_stripe_charge_id"`
-	StripeCustomerID    string       `json:"stripe_customer_id"`
-	StripeConnectIDHash string       `json:"stripe_connect_id_hash"`
-	AmountInCentsUSD    int64        `json:"amount_in_cents_usd"`
-	Notes               *string      `json:"notes"`
+	ID                  string         `json:"id"`
+	DonationType        DonationType   `json:"donation_type" form:"donation_type"`
+	Processor           Processor      `json:"processor"`
+	Status              DonationStatus `json:"status" form:"status"`
+	Livemode            bool           `json:"livemode"`
+	DonationDate        UnixTime       `json:"donation_date"`
+	AmountInCents       int64          `json:"amount_in_cents" form:"amount_in_cents"`
+	Currency            string         `json:"currency" form:"currency"`
+	Recurring           bool           `json:"recurring"`
+	Refunded            *bool          `json:"refunded"`
+	TransactionID       string         `json:"transaction_id"`
+	Created             UnixTime       `json:"created"`
+	Updated             UnixTime       `json:"updated"`
+	AmountFormatted     string         `json:"amount_formatted"`
+	Anonymous           bool           `json:"anonymous" form:"anonymous"`
+	OnBehalfOf          string         `json:"on_behalf_of" form:"on_behalf_of"`
+	Comment             string         `json:"comment" form:"comment"`
+	TrackingCodes       string         `json:"tracking_codes" form:"tracking_codes"`
+	MetaData            MetaData       `json:"meta_data"`
+	Person              Person         `json:"person" form:"email,ref=Email"`
+	Account             Account        `json:"account" form:"account_id,always"`
+	Campaign            Campaign       `json:"campaign" form:"campaign_id"`
+	Fundraiser          *Fundraiser    `json:"fundraiser" form:"fundraiser_id"`
+	Subscription        Subscription   `json:"subscription"`
+	Parent              any            `json:"parent"`
+	Refunds             []Refund       `json:"refunds"`
+	ChargeSource        ChargeSource   `json:"charge_source"`
+	ReferrerID          *string        `json:"referrer_id"`
+	RemoteIP            string         `json:"remote_ip"`
+	FeeInCents          int64          `json:"fee_in_cents"`
+	InternalID          int64          `json:"internal_id"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	FeeStripeChargeID   string         `json:"fee_stripe_charge_id"`
+	StripeCustomerID    string         `json:"stripe_customer_id"`
+	StripeConnectIDHash string         `json:"stripe_connect_id_hash"`
+	AmountInCentsUSD    int64          `json:"amount_in_cents_usd"`
+	Notes               *string        `json:"notes" form:"notes"`
+}
+
+// AttributedPersonID is the person a donation counts toward: the owner of the
+// fundraiser it was made through, or otherwise the donor.
+func (d Donation) AttributedPersonID() string {
+	if d.Fundraiser != nil && d.Fundraiser.Person.ID != "" {
+		return d.Fundraiser.Person.ID
+	}
+
+	return d.Person.ID
+}
+
+// Amount is the gift as Money.
+func (d Donation) Amount() Money {
+	return NewMoney(d.AmountInCents, d.Currency)
+}
+
+// NetAmount is what the gift counts toward a pledge or total: nothing for
+// test-mode, failed, pending or disputed gifts, and otherwise the amount less
+// whatever has been refunded. A gift marked refunded without any refund
+// records is taken as fully refunded.
+func (d Donation) NetAmount() Money {
+	zero := NewMoney(0, d.Currency)
+
+	if !d.Livemode {
+		return zero
+	}
+
+	switch d.Status {
+	case DonationProcessed, DonationRefunded:
+	default:
+		return zero
+	}
+
+	// Refunds go back in the currency the gift was made in, whatever Donately
+	// reports on the refund itself.
+	refunded := zero
+	for _, refund := range d.Refunds {
+		if refund.Counts() {
+			refunded = refunded.Add(NewMoney(refund.AmountInCents, d.Currency))
+		}
+	}
+
+	fullyRefunded := d.Refunded != nil && *d.Refunded
+	if len(d.Refunds) == 0 && (fullyRefunded || d.Status == DonationRefunded) {
+		return zero
+	}
+
+	net := d.Amount().Sub(refunded)
+	if net.IsNegative() {
+		return zero
+	}
+
+	return net
+}
+
+// Refund is a full or partial return of a donation's amount.
+type Refund struct {
+	ID            string       `json:"id"`
+	AmountInCents int64        `json:"amount_in_cents"`
+	Currency      string       `json:"currency"`
+	Status        RefundStatus `json:"status"`
+	Reason        string       `json:"reason"`
+	Created       UnixTime     `json:"created"`
+}
+
+func (r Refund) Amount() Money {
+	return NewMoney(r.AmountInCents, r.Currency)
+}
+
+// Counts reports whether the refund takes money back from its donation. Pending
+// refunds count, since they only ever complete; failed and canceled ones don't.
+func (r Refund) Counts() bool {
+	return r.Status != RefundFailed && r.Status != RefundCanceled
+}
+
+// GivenAt is when the gift was made: its donation date, or when Donately
+// recorded it if that's missing.
+func (d Donation) GivenAt() UnixTime {
+	if !d.DonationDate.IsZero() {
+		return d.DonationDate
+	}
+
+	return d.Created
+}
+
+// DateIn returns the day the gift was made in loc, usually the account's
+// Location.
+func (d Donation) DateIn(loc *time.Location) Date {
+	return DateOf(d.GivenAt().In(loc))
+}
+
+// WithAmount returns a copy of d for amount.
+func (d Donation) WithAmount(amount Money) Donation {
+	d.AmountInCents = amount.Cents
+	if amount.Currency != "" {
+		d.Currency = amount.Currency
+	}
+
+	return d
 }
 
 type MetaData struct {
@@ -83,10 +185,23 @@ type ChargeSource struct {
 
 type CollectionReportRecord struct {
 	FirstName, LastName, EmailAddress       string
-	AmountDonated, AmountDue, AmountPledged float64
+	AmountDonated, AmountDue, AmountPledged Money
 	Adjustments                             []Adjustment
 }
 
+// SourceRow identifies the report row a backfilled donation comes from: the
+// donor and the running total the report had for them.
+func (c CollectionReportRecord) SourceRow() string {
+	return fmt.Sprintf("%s|%s", strings.ToLower(c.EmailAddress), c.AmountDonated.Decimal())
+}
+
+// SourceRowHash is a digest of SourceRow for places, like traces, that need to
+// tell rows apart without carrying the donor's email.
+func (c CollectionReportRecord) SourceRowHash() string {
+	sum := sha256.Sum256([]byte(c.SourceRow()))
+	return hex.EncodeToString(sum[:8])
+}
+
 func ParseCollectionReportCSV(r io.ReadCloser) ([]CollectionReportRecord, error) {
 	defer r.Close()
 
@@ -103,53 +218,58 @@ func ParseCollectionReportCSV(r io.ReadCloser) ([]CollectionReportRecord, error)
 		return nil, err
 	}
 
+	// An optional "Currency" column among the trailing ones says which
+	// currency a row's amounts are in; without it they're in the account's.
+	currencyColumn := -1
+
+	for i := 6; i < len(columnHeaders); i++ {
+		if strings.EqualFold(strings.TrimSpace(columnHeaders[i]), "currency") {
+			currencyColumn = i
+		}
+	}
+
 	var reportRecords []CollectionReportRecord
 
-	for _, record := range records {
+	for i, record := range records {
+		// Rows are numbered as a spreadsheet would, counting the header.
+		row := i + 2
+
 		firstName := record[0]
 		lastName := record[1]
 		email := record[2]
-		rawDonationAmount := record[3]
-		rawDonationAmount = strings.ReplaceAll(rawDonationAmount, ",", "")
-		rawAmountDue := record[4]
-		rawAmountDue = strings.ReplaceAll(rawAmountDue, ",", "")
-		rawPledgedAmount := record[5]
-		rawPledgedAmount = strings.ReplaceAll(rawPledgedAmount, ",", "")
-
-		if rawDonationAmount == "" {
-			rawDonationAmount = "0"
-		}
 
-		amountDonated, err := strconv.ParseFloat(rawDonationAmount, 64)
-		if err != nil {
-			fmt.Printf("encountered an error parsing the following record: %+v", record)
-			return nil, err
+		var currency string
+		if currencyColumn >= 0 {
+			currency = strings.TrimSpace(record[currencyColumn])
 		}
 
-		if rawAmountDue == "" {
-			rawAmountDue = "0"
-		}
-
-		amountDue, err := strconv.ParseFloat(rawAmountDue, 64)
+		amountDonated, err := ParseMoney(record[3], currency)
 		if err != nil {
-			fmt.Printf("encountered an error parsing the following record: %+v", record)
-			return nil, err
+			return nil, fmt.Errorf("encountered an error parsing row %d of the collection report: %w", row, err)
 		}
 
-		if rawPledgedAmount == "" {
-			rawPledgedAmount = "0"
+		amountDue, err := ParseMoney(record[4], currency)
+		if err != nil {
+			return nil, fmt.Errorf("encountered an error parsing row %d of the collection report: %w", row, err)
 		}
 
-		amountPledged, err := strconv.ParseFloat(rawPledgedAmount, 64)
+		amountPledged, err := ParseMoney(record[5], currency)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("encountered an error parsing row %d of the collection report: %w", row, err)
 		}
 
 		if email == "" {
 			email = fmt.Sprintf("%v.%v@gmail.com", firstName, lastName)
 		}
 
-		adjustments := parseAdjustments(columnHeaders[6:], record[6:])
+		adjustmentNames, adjustmentValues := columnHeaders[6:], record[6:]
+
+		if currencyColumn >= 0 {
+			adjustmentNames = slices.Delete(slices.Clone(adjustmentNames), currencyColumn-6, currencyColumn-5)
+			adjustmentValues = slices.Delete(slices.Clone(adjustmentValues), currencyColumn-6, currencyColumn-5)
+		}
+
+		adjustments := parseAdjustments(adjustmentNames, adjustmentValues, currency)
 
 		reportRecords = append(reportRecords, CollectionReportRecord{
 			FirstName:     firstName,
@@ -165,7 +285,7 @@ func ParseCollectionReportCSV(r io.ReadCloser) ([]CollectionReportRecord, error)
 	return reportRecords, nil
 }
 
-func parseAdjustments(adjustmentNames, adjustmentValues []string) []Adjustment {
+func parseAdjustments(adjustmentNames, adjustmentValues []string, currency string) []Adjustment {
 	var adjustments []Adjustment
 
 	for i, value := range adjustmentValues {
@@ -173,10 +293,9 @@ func parseAdjustments(adjustmentNames, adjustmentValues []string) []Adjustment {
 			displayName := adjustmentNames[i]
 			slug := sluggify(displayName)
 
-			commalessValue := strings.ReplaceAll(value, ",", "")
-			amount, _ := strconv.ParseFloat(commalessValue, 64)
+			amount, _ := ParseMoney(value, currency)
 
-			if amount > 0 {
+			if amount.IsPositive() {
 				adjustments = append(adjustments, Adjustment{
 					DisplayName: adjustmentNames[i],
 					Slug:        slug,
@@ -0,0 +1,94 @@
+package donately
+
+import "testing"
+
+func TestNetAmount(t *testing.T) {
+	refunded := true
+
+	refund := func(cents int64, status RefundStatus) Refund {
+		return Refund{AmountInCents: cents, Currency: "usd", Status: status}
+	}
+
+	tests := []struct {
+		name     string
+		donation Donation
+		want     int64
+	}{
+		{
+			name:     "processed",
+			donation: Donation{Livemode: true, Status: DonationProcessed},
+			want:     10000,
+		},
+		{
+			name:     "test mode",
+			donation: Donation{Status: DonationProcessed},
+			want:     0,
+		},
+		{
+			name:     "pending",
+			donation: Donation{Livemode: true, Status: DonationPending},
+			want:     0,
+		},
+		{
+			name:     "failed",
+			donation: Donation{Livemode: true, Status: DonationFailed},
+			want:     0,
+		},
+		{
+			name:     "disputed",
+			donation: Donation{Livemode: true, Status: DonationDisputed},
+			want:     0,
+		},
+		{
+			name:     "refunded without refund records",
+			donation: Donation{Livemode: true, Status: DonationRefunded},
+			want:     0,
+		},
+		{
+			name:     "flagged refunded without refund records",
+			donation: Donation{Livemode: true, Status: DonationProcessed, Refunded: &refunded},
+			want:     0,
+		},
+		{
+			name:     "partially refunded",
+			donation: Donation{Livemode: true, Status: DonationProcessed, Refunds: []Refund{refund(2500, RefundSucceeded)}},
+			want:     7500,
+		},
+		{
+			name: "pending refunds count, failed and canceled ones don't",
+			donation: Donation{Livemode: true, Status: DonationRefunded, Refunds: []Refund{
+				refund(1000, RefundPending),
+				refund(2000, RefundFailed),
+				refund(3000, RefundCanceled),
+				refund(4000, RefundSucceeded),
+			}},
+			want: 5000,
+		},
+		{
+			name:     "refunded more than given",
+			donation: Donation{Livemode: true, Status: DonationRefunded, Refunds: []Refund{refund(6000, RefundSucceeded), refund(6000, RefundSucceeded)}},
+			want:     0,
+		},
+		{
+			name: "refund reported in another currency",
+			donation: Donation{Livemode: true, Status: DonationProcessed, Refunds: []Refund{
+				{AmountInCents: 1000, Currency: "cad", Status: RefundSucceeded},
+			}},
+			want: 9000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			donation := tt.donation
+			donation.AmountInCents = 10000
+			donation.Currency = "usd"
+
+			got := donation.NetAmount()
+
+			if want := NewMoney(tt.want, "usd"); got != want {
+				t.Errorf("NetAmount = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
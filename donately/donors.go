@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
@@ -14,16 +16,18 @@ import (
 )
 
 type Adjustment struct {
-	DisplayName string  `json:"name"`
-	Slug        string  `json:"slug"`
-	Amount      float64 `json:"amount"`
+	DisplayName string `json:"name"`
+	Slug        string `json:"slug"`
+	Amount      Money  `json:"amount"`
 }
 
 type Donor struct {
 	Person      Person       `json:"person"`
-	Pledge      float64      `json:"pledge"`
+	Pledge      Money        `json:"pledge"`
+	Donated     Money        `json:"donated"`
 	Donations   []Donation   `json:"donations"`
 	Adjustments []Adjustment `json:"adjustments"`
+	Fundraiser  *Fundraiser  `json:"fundraiser,omitempty"`
 }
 
 type AdjustmentStore interface {
@@ -54,17 +58,18 @@ func asAdjustment(adjustment donors.DonorAdjustment) Adjustment {
 	return Adjustment{
 		DisplayName: adjustment.DisplayName.String,
 		Slug:        adjustment.Slug.String,
-		Amount:      adjustment.Amount.Float64,
+		Amount:      NewMoney(adjustment.AmountInCents.Int64, adjustment.Currency.String),
 	}
 }
 
 func (d defaultAdjustmentStore) SaveAdjustments(ctx context.Context, person Person, adjustments []Adjustment) error {
 	for _, adjustment := range adjustments {
 		_, err := d.queries.SaveDonorAdjustment(ctx, donors.SaveDonorAdjustmentParams{
-			PersonID:    sql.NullString{String: person.ID, Valid: true},
-			Slug:        sql.NullString{String: adjustment.Slug, Valid: true},
-			DisplayName: sql.NullString{String: adjustment.DisplayName, Valid: true},
-			Amount:      sql.NullFloat64{Float64: adjustment.Amount, Valid: true},
+			PersonID:      sql.NullString{String: person.ID, Valid: true},
+			Slug:          sql.NullString{String: adjustment.Slug, Valid: true},
+			DisplayName:   sql.NullString{String: adjustment.DisplayName, Valid: true},
+			AmountInCents: sql.NullInt64{Int64: adjustment.Amount.Cents, Valid: true},
+			Currency:      sql.NullString{String: adjustment.Amount.Currency, Valid: adjustment.Amount.Currency != ""},
 		})
 
 		if err != nil {
@@ -75,6 +80,48 @@ func (d defaultAdjustmentStore) SaveAdjustments(ctx context.Context, person Pers
 	return nil
 }
 
+type memoryAdjustmentStore struct {
+	mu          sync.Mutex
+	adjustments map[string]map[string]Adjustment
+}
+
+// NewMemoryAdjustmentStore returns an AdjustmentStore that lives as long as the
+// process, for runs that mustn't touch the database.
+func NewMemoryAdjustmentStore() AdjustmentStore {
+	return &memoryAdjustmentStore{adjustments: map[string]map[string]Adjustment{}}
+}
+
+func (m *memoryAdjustmentStore) GetAdustmentsByPerson(_ context.Context, person Person) ([]Adjustment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var adjustments []Adjustment
+	for _, adjustment := range m.adjustments[person.ID] {
+		adjustments = append(adjustments, adjustment)
+	}
+
+	slices.SortFunc(adjustments, func(a, b Adjustment) int {
+		return strings.Compare(a.Slug, b.Slug)
+	})
+
+	return adjustments, nil
+}
+
+func (m *memoryAdjustmentStore) SaveAdjustments(_ context.Context, person Person, adjustments []Adjustment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.adjustments[person.ID] == nil {
+		m.adjustments[person.ID] = map[string]Adjustment{}
+	}
+
+	for _, adjustment := range adjustments {
+		m.adjustments[person.ID][adjustment.Slug] = adjustment
+	}
+
+	return nil
+}
+
 func NewAdjustmentStore() (AdjustmentStore, error) {
 	databaseURL := os.Getenv("DATABASE_URL")
 
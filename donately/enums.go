@@ -0,0 +1,146 @@
+package donately
+
+// The enumerations below are strings on the wire. Values Donately adds later
+// still decode and round-trip unchanged; Valid reports whether a value is one
+// this package knows, so callers can switch over the constants and treat
+// anything else as unknown.
+
+type DonationStatus string
+
+const (
+	DonationProcessed DonationStatus = "processed"
+	DonationPending   DonationStatus = "pending"
+	DonationFailed    DonationStatus = "failed"
+	DonationRefunded  DonationStatus = "refunded"
+	DonationDisputed  DonationStatus = "disputed"
+)
+
+func (s DonationStatus) Valid() bool {
+	switch s {
+	case DonationProcessed, DonationPending, DonationFailed, DonationRefunded, DonationDisputed:
+		return true
+	}
+
+	return false
+}
+
+func (s DonationStatus) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (s *DonationStatus) UnmarshalText(text []byte) error {
+	*s = DonationStatus(text)
+	return nil
+}
+
+type DonationType string
+
+const (
+	DonationTypeCard   DonationType = "cc"
+	DonationTypeACH    DonationType = "ach"
+	DonationTypeCash   DonationType = "cash"
+	DonationTypeCheck  DonationType = "check"
+	DonationTypePayPal DonationType = "paypal"
+	DonationTypeInKind DonationType = "in_kind"
+	DonationTypeOther  DonationType = "other"
+)
+
+func (t DonationType) Valid() bool {
+	switch t {
+	case DonationTypeCard, DonationTypeACH, DonationTypeCash, DonationTypeCheck, DonationTypePayPal, DonationTypeInKind, DonationTypeOther:
+		return true
+	}
+
+	return false
+}
+
+func (t DonationType) MarshalText() ([]byte, error) {
+	return []byte(t), nil
+}
+
+func (t *DonationType) UnmarshalText(text []byte) error {
+	*t = DonationType(text)
+	return nil
+}
+
+// Processor is who moved the money for a gift. Offline gifts (cash, checks)
+// have none.
+type Processor string
+
+const (
+	ProcessorNone   Processor = ""
+	ProcessorStripe Processor = "stripe"
+	ProcessorPayPal Processor = "paypal"
+)
+
+func (p Processor) Valid() bool {
+	switch p {
+	case ProcessorNone, ProcessorStripe, ProcessorPayPal:
+		return true
+	}
+
+	return false
+}
+
+func (p Processor) MarshalText() ([]byte, error) {
+	return []byte(p), nil
+}
+
+func (p *Processor) UnmarshalText(text []byte) error {
+	*p = Processor(text)
+	return nil
+}
+
+type RecurringFrequency string
+
+const (
+	RecurringWeekly    RecurringFrequency = "weekly"
+	RecurringMonthly   RecurringFrequency = "monthly"
+	RecurringQuarterly RecurringFrequency = "quarterly"
+	RecurringYearly    RecurringFrequency = "yearly"
+)
+
+func (f RecurringFrequency) Valid() bool {
+	switch f {
+	case RecurringWeekly, RecurringMonthly, RecurringQuarterly, RecurringYearly:
+		return true
+	}
+
+	return false
+}
+
+func (f RecurringFrequency) MarshalText() ([]byte, error) {
+	return []byte(f), nil
+}
+
+func (f *RecurringFrequency) UnmarshalText(text []byte) error {
+	*f = RecurringFrequency(text)
+	return nil
+}
+
+type RefundStatus string
+
+const (
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundPending   RefundStatus = "pending"
+	RefundFailed    RefundStatus = "failed"
+	RefundCanceled  RefundStatus = "canceled"
+)
+
+func (s RefundStatus) Valid() bool {
+	switch s {
+	case RefundSucceeded, RefundPending, RefundFailed, RefundCanceled:
+		return true
+	}
+
+	return false
+}
+
+func (s RefundStatus) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (s *RefundStatus) UnmarshalText(text []byte) error {
+	*s = RefundStatus(text)
+	return nil
+}
@@ -0,0 +1,75 @@
+package donately
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Fundraiser is a peer-to-peer fundraising page that a person runs on behalf
+// of a campaign.
+type Fundraiser struct {
+	ID                  string         `json:"id"`
+	Title               string         `json:"title" form:"title"`
+	Slug                string         `json:"slug"`
+	Status              string         `json:"status" form:"status"`
+	URL                 string         `json:"url"`
+	Permalink           string         `json:"permalink"`
+	Description         *string        `json:"description" form:"description"`
+	Content             *string        `json:"content" form:"content"`
+	Created             UnixTime       `json:"created"`
+	Updated             UnixTime       `json:"updated"`
+	GoalInCents         int64          `json:"goal_in_cents" form:"goal_in_cents"`
+	AmountRaisedInCents int64          `json:"amount_raised_in_cents"`
+	PercentFunded       float64        `json:"percent_funded"`
+	DonorsCount         int            `json:"donors_count"`
+	Person              Person         `json:"person" form:"person_id"`
+	Campaign            Campaign       `json:"campaign" form:"campaign_id"`
+	Account             Account        `json:"account" form:"account_id,always"`
+	Images              CampaignImages `json:"images"`
+	MetaData            any            `json:"meta_data"`
+	InternalID          int64          `json:"internal_id"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
+
+// UnmarshalJSON accepts either a full fundraiser object or a bare fundraiser
+// ID, since donations and subscriptions sometimes reference fundraisers by ID
+// only.
+func (f *Fundraiser) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*f = Fundraiser{ID: id}
+		return nil
+	}
+
+	type fundraiser Fundraiser
+
+	var decoded fundraiser
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*f = Fundraiser(decoded)
+
+	return nil
+}
+
+// ResolveFundraisers replaces ID-only fundraiser references on donations with
+// the full fundraiser from fundraisers, so attribution can see who runs it.
+func ResolveFundraisers(donations []Donation, fundraisers []Fundraiser) {
+	byID := make(map[string]Fundraiser, len(fundraisers))
+
+	for _, fundraiser := range fundraisers {
+		byID[fundraiser.ID] = fundraiser
+	}
+
+	for i, donation := range donations {
+		if donation.Fundraiser == nil {
+			continue
+		}
+
+		if full, present := byID[donation.Fundraiser.ID]; present {
+			donations[i].Fundraiser = &full
+		}
+	}
+}
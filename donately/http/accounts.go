@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+// ResolveAccount finds the account ref names, whether ref is an account ID or
+// the subdomain or title of one of the caller's accounts. Titles must match
+// exactly one account.
+func ResolveAccount(ctx context.Context, client Client, ref string) (donately.Account, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return donately.Account{}, errors.New("missing account")
+	}
+
+	account, err := client.FindAccount(ctx, ref)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return donately.Account{}, err
+	}
+
+	accounts, err := client.MyAccounts(ctx)
+	if err != nil {
+		return donately.Account{}, err
+	}
+
+	for _, account := range accounts {
+		if strings.EqualFold(account.Subdomain, ref) {
+			return client.FindAccount(ctx, account.ID)
+		}
+	}
+
+	var matches []donately.Account
+	for _, account := range accounts {
+		if strings.EqualFold(account.Title, ref) {
+			matches = append(matches, account)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return donately.Account{}, fmt.Errorf("no account with id, subdomain or title %q: %w", ref, ErrNotFound)
+	case 1:
+		return client.FindAccount(ctx, matches[0].ID)
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, account := range matches {
+		ids = append(ids, account.ID)
+	}
+
+	return donately.Account{}, fmt.Errorf("%d accounts are titled %q (%s); use an id or subdomain instead", len(matches), ref, strings.Join(ids, ", "))
+}
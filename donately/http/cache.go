@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+// cachingClient decorates a Client, serving account, campaign, people and
+// donation reads from a CacheStore for up to ttl. Writes through the client
+// invalidate whatever they could have changed.
+type cachingClient struct {
+	Client
+
+	store  donately.CacheStore
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// NewCachingClient wraps client with a read cache kept in store.
+func NewCachingClient(client Client, store donately.CacheStore, ttl time.Duration, logger *slog.Logger) Client {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &cachingClient{Client: client, store: store, ttl: ttl, logger: logger}
+}
+
+// cached returns the value stored under key if it is fresh, and otherwise
+// loads and stores it. A broken cache only costs a trip to Donately.
+func cached[T any](ctx context.Context, c *cachingClient, key string, load func() (T, error)) (T, error) {
+	raw, storedAt, ok, err := c.store.GetCacheEntry(ctx, key)
+	if err != nil {
+		c.logger.Warn("encountered an error reading the cache", slog.String("key", key), slog.Any("error", err))
+	}
+
+	if ok && time.Since(storedAt) < c.ttl {
+		var value T
+		if err := json.Unmarshal(raw, &value); err == nil {
+			c.logger.Debug("cache hit", slog.String("key", key))
+			return value, nil
+		}
+	}
+
+	c.logger.Debug("cache miss", slog.String("key", key))
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	if raw, err := json.Marshal(value); err == nil {
+		if err := c.store.SaveCacheEntry(ctx, key, raw); err != nil {
+			c.logger.Warn("encountered an error writing to the cache", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+
+	return value, nil
+}
+
+func (c *cachingClient) maxConcurrency() int {
+	return concurrencyOf(c.Client)
+}
+
+func (c *cachingClient) invalidate(ctx context.Context, prefixes ...string) {
+	for _, prefix := range prefixes {
+		if err := c.store.DeleteCacheEntries(ctx, prefix); err != nil {
+			c.logger.Warn("encountered an error invalidating the cache", slog.String("prefix", prefix), slog.Any("error", err))
+		}
+	}
+}
+
+func accountKey(id string) string {
+	return "account:" + id
+}
+
+func campaignsKey(account donately.Account) string {
+	return "campaign:" + account.ID + ":"
+}
+
+func peopleKey(account donately.Account) string {
+	return "people:" + account.ID + ":"
+}
+
+func donationsKey(account donately.Account) string {
+	return "donations:" + account.ID + ":"
+}
+
+func pageKey(prefix string, params url.Values, offset, limit int) string {
+	return fmt.Sprintf("%s%s:%d:%d", prefix, params.Encode(), offset, limit)
+}
+
+func (c *cachingClient) FindAccount(ctx context.Context, id string) (donately.Account, error) {
+	return cached(ctx, c, accountKey(id), func() (donately.Account, error) {
+		return c.Client.FindAccount(ctx, id)
+	})
+}
+
+func (c *cachingClient) FindCampaign(ctx context.Context, id string, account donately.Account) (donately.Campaign, error) {
+	return cached(ctx, c, campaignsKey(account)+id, func() (donately.Campaign, error) {
+		return c.Client.FindCampaign(ctx, id, account)
+	})
+}
+
+func (c *cachingClient) ListPeople(ctx context.Context, account donately.Account, filter PersonFilter, offset, limit int) ([]donately.Person, error) {
+	params := url.Values{}
+	filter.apply(params)
+
+	return cached(ctx, c, pageKey(peopleKey(account), params, offset, limit), func() ([]donately.Person, error) {
+		return c.Client.ListPeople(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *cachingClient) People(ctx context.Context, account donately.Account, filter PersonFilter) iter.Seq2[donately.Person, error] {
+	return paginate(ctx, defaultPageSize, c.maxConcurrency(), func(ctx context.Context, offset, limit int) ([]donately.Person, error) {
+		return c.ListPeople(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *cachingClient) ListDonations(ctx context.Context, account donately.Account, filter DonationFilter, offset, limit int) ([]donately.Donation, error) {
+	params := url.Values{}
+	filter.apply(params)
+
+	return cached(ctx, c, pageKey(donationsKey(account), params, offset, limit), func() ([]donately.Donation, error) {
+		return c.Client.ListDonations(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *cachingClient) Donations(ctx context.Context, account donately.Account, filter DonationFilter) iter.Seq2[donately.Donation, error] {
+	return paginate(ctx, defaultPageSize, c.maxConcurrency(), func(ctx context.Context, offset, limit int) ([]donately.Donation, error) {
+		return c.ListDonations(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *cachingClient) SavePerson(ctx context.Context, person donately.Person) (donately.Person, error) {
+	saved, err := c.Client.SavePerson(ctx, person)
+
+	for _, account := range person.Accounts {
+		// Donations embed their donor, so they are stale too.
+		c.invalidate(ctx, peopleKey(account), donationsKey(account))
+	}
+
+	return saved, err
+}
+
+func (c *cachingClient) SaveDonation(ctx context.Context, donation donately.Donation) (donately.Donation, error) {
+	saved, err := c.Client.SaveDonation(ctx, donation)
+	c.invalidateDonations(ctx, donation.Account)
+	return saved, err
+}
+
+func (c *cachingClient) RefundDonation(ctx context.Context, donation donately.Donation, reason string) error {
+	err := c.Client.RefundDonation(ctx, donation, reason)
+	c.invalidateDonations(ctx, donation.Account)
+	return err
+}
+
+// invalidateDonations also drops cached campaigns, whose raised totals move
+// with every gift.
+func (c *cachingClient) invalidateDonations(ctx context.Context, account donately.Account) {
+	c.invalidate(ctx, donationsKey(account), campaignsKey(account))
+}
+
+func (c *cachingClient) SaveCampaign(ctx context.Context, campaign donately.Campaign) (donately.Campaign, error) {
+	saved, err := c.Client.SaveCampaign(ctx, campaign)
+	c.invalidate(ctx, campaignsKey(campaign.Account))
+	return saved, err
+}
+
+func (c *cachingClient) PatchCampaign(ctx context.Context, original, updated donately.Campaign) (donately.Campaign, error) {
+	saved, err := c.Client.PatchCampaign(ctx, original, updated)
+	c.invalidate(ctx, campaignsKey(updated.Account))
+	return saved, err
+}
+
+func (c *cachingClient) DeleteCampaign(ctx context.Context, campaign donately.Campaign) error {
+	err := c.Client.DeleteCampaign(ctx, campaign)
+	c.invalidate(ctx, campaignsKey(campaign.Account))
+	return err
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Interaction is one recorded Donately request/response pair. URLs are stored
+// without scheme and host so a cassette recorded against production replays
+// against any base URL.
+type Interaction struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	RequestBody  string      `json:"request_body,omitempty"`
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	ResponseBody string      `json:"response_body"`
+}
+
+// secretParams are scrubbed from recorded URLs and bodies. Unlike the log
+// redaction list, donor fields stay intact so replayed requests still match.
+var secretParams = []string{"api_key", "token", "access_token"}
+
+// scrubbedResponseHeaders never make it into a cassette.
+var scrubbedResponseHeaders = []string{"Set-Cookie", "Authorization"}
+
+func interactionKey(method, rawURL, body string) string {
+	return method + " " + rawURL + "\n" + body
+}
+
+func cassetteURL(u *url.URL) string {
+	query := u.Query()
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+
+	if len(query) == 0 {
+		return u.Path
+	}
+
+	return u.Path + "?" + query.Encode()
+}
+
+func scrubBody(body string) string {
+	values, err := url.ParseQuery(body)
+	if err != nil || len(values) == 0 || strings.HasPrefix(strings.TrimSpace(body), "{") {
+		return body
+	}
+
+	for _, param := range secretParams {
+		if values.Has(param) {
+			values.Set(param, redacted)
+		}
+	}
+
+	return values.Encode()
+}
+
+func readBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return nil, body, nil
+	}
+
+	defer body.Close()
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return raw, io.NopCloser(bytes.NewReader(raw)), nil
+}
+
+// RecordingTransport forwards requests to the next RoundTripper and appends
+// every exchange to a JSONL cassette.
+type RecordingTransport struct {
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	out *os.File
+	enc *json.Encoder
+}
+
+func NewRecordingTransport(path string, next http.RoundTripper) (*RecordingTransport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+
+	return &RecordingTransport{next: next, out: out, enc: json.NewEncoder(out)}, nil
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, body, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for recording: %w", err)
+	}
+	req.Body = body
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, body, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body for recording: %w", err)
+	}
+	resp.Body = body
+
+	header := resp.Header.Clone()
+	for _, name := range scrubbedResponseHeaders {
+		header.Del(name)
+	}
+
+	interaction := Interaction{
+		Method:       req.Method,
+		URL:          cassetteURL(req.URL),
+		RequestBody:  scrubBody(string(reqBody)),
+		Status:       resp.StatusCode,
+		Header:       header,
+		ResponseBody: string(respBody),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.enc.Encode(interaction); err != nil {
+		return nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (t *RecordingTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.out.Close()
+}
+
+// ReplayTransport serves responses from a cassette without touching the
+// network. Identical requests are answered in recorded order; once a request's
+// recordings are used up, its last response keeps being served so long-running
+// commands like serve can reload pages. Unrecorded requests get a 501 error
+// envelope rather than a transport error, so they are not retried.
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer in.Close()
+
+	t := &ReplayTransport{
+		interactions: map[string][]Interaction{},
+		served:       map[string]int{},
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("cassette line %d: %w", line, err)
+		}
+
+		key := interactionKey(interaction.Method, interaction.URL, interaction.RequestBody)
+		t.interactions[key] = append(t.interactions[key], interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	if len(t.interactions) == 0 {
+		return nil, errors.New("cassette is empty")
+	}
+
+	return t, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, _, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for replay: %w", err)
+	}
+
+	key := interactionKey(req.Method, cassetteURL(req.URL), scrubBody(string(reqBody)))
+
+	t.mu.Lock()
+	recorded := t.interactions[key]
+	n := t.served[key]
+	t.served[key] = n + 1
+	t.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return replayMiss(req), nil
+	}
+
+	interaction := recorded[min(n, len(recorded)-1)]
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+func replayMiss(req *http.Request) *http.Response {
+	body, _ := json.Marshal(APIResponse{
+		Type:    "replay_error",
+		Code:    "cassette_miss",
+		Message: fmt.Sprintf("no recorded interaction for %s %s", req.Method, cassetteURL(req.URL)),
+	})
+
+	return &http.Response{
+		Status:        "501 Not Implemented",
+		StatusCode:    http.StatusNotImplemented,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -7,42 +7,65 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/cenkalti/backoff/v5"
 	"github.com/willmadison/donately-sync-tools/donately"
+	"golang.org/x/time/rate"
 )
 
 type Client interface {
-	FindAccount(string) (donately.Account, error)
-	ListPeople(donately.Account, int, int) ([]donately.Person, error)
-	FindPerson(string, donately.Account) (donately.Person, error)
-	Me() (donately.Person, error)
-	SavePerson(donately.Person) (donately.Person, error)
-	ListDonations(donately.Account, int, int) ([]donately.Donation, error)
-	ListMyDonations() ([]donately.Donation, error)
-	FindDonation(string, donately.Account) (donately.Donation, error)
-	SaveDonation(donately.Donation) (donately.Donation, error)
-	RefundDonation(donately.Donation, string) error
-	SendDonationReceipt(donately.Donation) error
-	ListSubscriptions(donately.Account) ([]donately.Subscription, error)
-	ListMySubscriptions() ([]donately.Subscription, error)
-	FindSubscription(string, donately.Account) (donately.Subscription, error)
-	SaveSubscription(donately.Subscription) (donately.Subscription, error)
-	ListCampaigns(donately.Account) ([]donately.Campaign, error)
-	FindCampaign(string, donately.Account) (donately.Campaign, error)
-	SaveCampaign(donately.Campaign) (donately.Campaign, error)
-	DeleteCampaign(donately.Campaign) error
+	FindAccount(context.Context, string) (donately.Account, error)
+	ListAccounts(context.Context, int, int) ([]donately.Account, error)
+	Accounts(context.Context) iter.Seq2[donately.Account, error]
+	MyAccounts(context.Context) ([]donately.Account, error)
+	ListPeople(context.Context, donately.Account, PersonFilter, int, int) ([]donately.Person, error)
+	People(context.Context, donately.Account, PersonFilter) iter.Seq2[donately.Person, error]
+	FindPerson(context.Context, string, donately.Account) (donately.Person, error)
+	Me(context.Context) (donately.Person, error)
+	SavePerson(context.Context, donately.Person) (donately.Person, error)
+	ListDonations(context.Context, donately.Account, DonationFilter, int, int) ([]donately.Donation, error)
+	Donations(context.Context, donately.Account, DonationFilter) iter.Seq2[donately.Donation, error]
+	ListMyDonations(context.Context) ([]donately.Donation, error)
+	FindDonation(context.Context, string, donately.Account) (donately.Donation, error)
+	SaveDonation(context.Context, donately.Donation) (donately.Donation, error)
+	RefundDonation(context.Context, donately.Donation, string) error
+	SendDonationReceipt(context.Context, donately.Donation) error
+	ListSubscriptions(context.Context, donately.Account, SubscriptionFilter, int, int) ([]donately.Subscription, error)
+	Subscriptions(context.Context, donately.Account, SubscriptionFilter) iter.Seq2[donately.Subscription, error]
+	ListMySubscriptions(context.Context) ([]donately.Subscription, error)
+	FindSubscription(context.Context, string, donately.Account) (donately.Subscription, error)
+	SaveSubscription(context.Context, donately.Subscription) (donately.Subscription, error)
+	PatchSubscription(context.Context, donately.Subscription, donately.Subscription) (donately.Subscription, error)
+	ListCampaigns(context.Context, donately.Account, CampaignFilter, int, int) ([]donately.Campaign, error)
+	Campaigns(context.Context, donately.Account, CampaignFilter) iter.Seq2[donately.Campaign, error]
+	FindCampaign(context.Context, string, donately.Account) (donately.Campaign, error)
+	SaveCampaign(context.Context, donately.Campaign) (donately.Campaign, error)
+	PatchCampaign(context.Context, donately.Campaign, donately.Campaign) (donately.Campaign, error)
+	DeleteCampaign(context.Context, donately.Campaign) error
+	ListFundraisers(context.Context, donately.Account, FundraiserFilter, int, int) ([]donately.Fundraiser, error)
+	Fundraisers(context.Context, donately.Account, FundraiserFilter) iter.Seq2[donately.Fundraiser, error]
+	FindFundraiser(context.Context, string, donately.Account) (donately.Fundraiser, error)
+	SaveFundraiser(context.Context, donately.Fundraiser) (donately.Fundraiser, error)
 }
 
 type donatelyClient struct {
-	APIKey  string
-	BaseURL string
-	client  *http.Client
+	APIKey      string
+	BaseURL     string
+	client      *http.Client
+	apiVersion  string
+	userAgent   string
+	logger      *slog.Logger
+	retryPolicy RetryPolicy
+	rateLimit   RateLimit
+	limiter     *rate.Limiter
+	concurrency int
 }
 
 type APIResponse struct {
@@ -53,52 +76,81 @@ type APIResponse struct {
 	RequestID string          `json:"request_id"`
 }
 
-func NewDonatelyClient() (Client, error) {
-	apiKey := os.Getenv("DONATELY_API_KEY")
+// NewDonatelyClient builds a client configured by opts. Anything not set
+// explicitly falls back to the DONATELY_API_KEY, DONATELY_API_VERSION,
+// DONATELY_RATE_LIMIT and DONATELY_RATE_BURST environment variables, then to
+// the package defaults.
+func NewDonatelyClient(opts ...Option) (Client, error) {
+	rateLimit, err := rateLimitFromEnv()
+	if err != nil {
+		return &donatelyClient{}, err
+	}
+
+	apiVersion := os.Getenv("DONATELY_API_VERSION")
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+
+	c := &donatelyClient{
+		APIKey:      os.Getenv("DONATELY_API_KEY"),
+		BaseURL:     DefaultBaseURL,
+		client:      &http.Client{},
+		apiVersion:  apiVersion,
+		userAgent:   DefaultUserAgent,
+		logger:      slog.Default(),
+		retryPolicy: DefaultRetryPolicy(),
+		rateLimit:   rateLimit,
+		concurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
 
-	if apiKey == "" {
+	if c.APIKey == "" {
 		return &donatelyClient{}, errors.New("missing Donately API key")
 	}
 
-	return &donatelyClient{
-		APIKey:  apiKey,
-		BaseURL: "https://api.donately.com/v2",
-		client:  &http.Client{},
-	}, nil
-}
+	c.limiter = limiterFor(c.APIKey, c.rateLimit)
 
-type retryable interface {
-	CanRetry() bool
+	return c, nil
 }
 
-type retryableError struct {
-	Err      error
-	canRetry bool
-}
+// rateLimitFromEnv reads DONATELY_RATE_LIMIT (requests/second) and
+// DONATELY_RATE_BURST, falling back to DefaultRateLimit for anything unset.
+func rateLimitFromEnv() (RateLimit, error) {
+	limit := DefaultRateLimit()
 
-func (e retryableError) Error() string {
-	return e.Err.Error()
-}
+	if raw := os.Getenv("DONATELY_RATE_LIMIT"); raw != "" {
+		rps, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return limit, fmt.Errorf("invalid DONATELY_RATE_LIMIT %q: %w", raw, err)
+		}
+		limit.RequestsPerSecond = rps
+	}
 
-func (e retryableError) Unwrap() error {
-	return e.Err
-}
+	if raw := os.Getenv("DONATELY_RATE_BURST"); raw != "" {
+		burst, err := strconv.Atoi(raw)
+		if err != nil {
+			return limit, fmt.Errorf("invalid DONATELY_RATE_BURST %q: %w", raw, err)
+		}
+		limit.Burst = burst
+	}
 
-func (e retryableError) CanRetry() bool {
-	return e.canRetry
+	return limit, nil
 }
 
-func (c *donatelyClient) makeRequest(method, endpoint string, body any) (*APIResponse, error) {
-	return c.makeRequestWithContentType(method, endpoint, body, "application/json")
+func (c *donatelyClient) makeRequest(ctx context.Context, method, endpoint string, body any) (*APIResponse, error) {
+	return c.makeRequestWithContentType(ctx, method, endpoint, body, "application/json")
 }
 
-func (c *donatelyClient) makeRequestWithContentType(method, endpoint string, body any, contentType string) (*APIResponse, error) {
-	var reqBody io.Reader
+func (c *donatelyClient) makeRequestWithContentType(ctx context.Context, method, endpoint string, body any, contentType string) (*APIResponse, error) {
+	var payload []byte
 	if body != nil {
 		switch contentType {
 		case "application/x-www-form-urlencoded":
 			if formData, ok := body.(url.Values); ok {
-				reqBody = strings.NewReader(formData.Encode())
+				payload = []byte(formData.Encode())
 			} else {
 				return nil, fmt.Errorf("body must be url.Values for form-encoded requests")
 			}
@@ -107,69 +159,173 @@ func (c *donatelyClient) makeRequestWithContentType(method, endpoint string, bod
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal request body: %w", err)
 			}
-			reqBody = bytes.NewReader(jsonBody)
+			payload = jsonBody
 		}
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL+endpoint, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	return c.retryPolicy.do(ctx, c.logger, method, func(attempt int) (*APIResponse, error) {
+		return c.doRequest(ctx, attempt, method, endpoint, payload, contentType)
+	})
+}
+
+func (c *donatelyClient) doRequest(ctx context.Context, attempt int, method, endpoint string, payload []byte, contentType string) (*APIResponse, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
 	}
 
-	donatelyVersion := os.Getenv("DONATELY_API_VERSION")
+	ctx, span := startRequestSpan(ctx, method, endpoint, attempt)
+	defer span.End()
 
-	if donatelyVersion == "" {
-		donatelyVersion = "2019-03-15"
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Donately-Version", donatelyVersion)
+	req.Header.Set("Donately-Version", c.apiVersion)
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
 
-	requestLine := fmt.Sprintf("%s %s %s", req.Method, req.URL.RequestURI(), req.Proto)
+	logger := c.logger.With(
+		slog.String("method", req.Method),
+		slog.String("path", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+	)
 
-	fmt.Println("Issuing request", requestLine)
+	if err := c.waitForRateLimit(ctx, logger); err != nil {
+		finishRequestSpan(span, 0, "", err)
+		return nil, err
+	}
+
+	logger.Debug("issuing donately request", slog.String("api_key", redactSecret(c.APIKey)))
+
+	startedAt := time.Now()
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			finishRequestSpan(span, 0, "", ctxErr)
+			return nil, ctxErr
+		}
+
+		err = redactTransportError(err, req.URL)
+		logger.Warn("donately request failed", slog.Duration("duration", time.Since(startedAt)), slog.Any("error", err))
+		observeClientRequest(method, endpoint, attempt, 0, time.Since(startedAt))
+		finishRequestSpan(span, 0, "", err)
+
+		return nil, retryableError{Err: fmt.Errorf("failed to make request: %w", err), rejected: isDialError(err)}
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		observeClientRequest(method, endpoint, attempt, resp.StatusCode, time.Since(startedAt))
+		finishRequestSpan(span, resp.StatusCode, "", err)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	apiResp, err := parseResponse(req, resp.StatusCode, respBody)
+
+	// "retry later" bodies arrive with a 200 but are recorded as throttling.
+	status := resp.StatusCode
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	observeClientRequest(method, endpoint, attempt, status, time.Since(startedAt))
+
+	requestID := responseRequestID(apiResp, err)
+	finishRequestSpan(span, status, requestID, err)
+
+	attrs := []any{
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", time.Since(startedAt)),
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	if err != nil {
+		logger.Warn("donately request returned an error", append(attrs, slog.Any("error", err))...)
+
+		switch {
+		case errors.Is(err, ErrRateLimited):
+			// Only a real 429 turns the request away unprocessed. A "retry
+			// later" body on a 200 can follow a write that went through.
+			rejected := resp.StatusCode == http.StatusTooManyRequests
+			return nil, retryableError{Err: err, rejected: rejected, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		case isRetryableStatus(resp.StatusCode):
+			return nil, retryableError{Err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+
+		return nil, err
+	}
+
+	logger.Info("donately request", attrs...)
+
+	return apiResp, nil
+}
+
+func responseRequestID(apiResp *APIResponse, err error) string {
+	if apiResp != nil {
+		return apiResp.RequestID
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RequestID
+	}
+
+	return ""
+}
+
+func parseResponse(req *http.Request, status int, respBody []byte) (*APIResponse, error) {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     req.Method,
+		Endpoint:   req.URL.Path,
+	}
+
 	var apiResp APIResponse
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		rawBody := string(respBody)
-
-		errorReturned := fmt.Errorf("failed to unmarshal response: %w", err)
+		if isRetryLater(respBody) {
+			apiErr.StatusCode = http.StatusTooManyRequests
+			apiErr.Message = "retry later"
+			return nil, apiErr
+		}
 
-		if "retry later" == strings.ToLower(strings.TrimSpace(rawBody)) {
-			return nil, retryableError{Err: errorReturned, canRetry: true}
+		if status >= 400 {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+			return nil, apiErr
 		}
 
-		return nil, errorReturned
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if apiResp.Type != "" && apiResp.Message != "" && apiResp.Code != "" {
-		return nil, fmt.Errorf("API error: %s - (%s) %s", apiResp.Code, apiResp.Type, apiResp.Message)
+		apiErr.Code = apiResp.Code
+		apiErr.Type = apiResp.Type
+		apiErr.Message = apiResp.Message
+		apiErr.RequestID = apiResp.RequestID
+		return nil, apiErr
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP error: %d (Raw Response: %v)", resp.StatusCode, apiResp)
+	if status >= 400 {
+		apiErr.Message = apiResp.Message
+		apiErr.RequestID = apiResp.RequestID
+		return nil, apiErr
 	}
 
 	return &apiResp, nil
 }
 
-func (c *donatelyClient) FindAccount(id string) (donately.Account, error) {
+func (c *donatelyClient) FindAccount(ctx context.Context, id string) (donately.Account, error) {
 	endpoint := fmt.Sprintf("/accounts/%s", url.PathEscape(id))
 
-	resp, err := c.makeRequest(http.MethodGet, endpoint, nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return donately.Account{}, err
 	}
@@ -182,9 +338,44 @@ func (c *donatelyClient) FindAccount(id string) (donately.Account, error) {
 	return account, nil
 }
 
-func (c *donatelyClient) ListPeople(account donately.Account, offset, limit int) ([]donately.Person, error) {
+func (c *donatelyClient) ListAccounts(ctx context.Context, offset, limit int) ([]donately.Account, error) {
+	params := url.Values{}
+
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/accounts?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []donately.Account
+	if err := json.Unmarshal(resp.Data, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// MyAccounts returns the accounts the API key's owner belongs to.
+func (c *donatelyClient) MyAccounts(ctx context.Context) ([]donately.Account, error) {
+	me, err := c.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return me.Accounts, nil
+}
+
+func (c *donatelyClient) ListPeople(ctx context.Context, account donately.Account, filter PersonFilter, offset, limit int) ([]donately.Person, error) {
 	params := url.Values{}
 	params.Set("account_id", account.ID)
+	filter.apply(params)
 
 	if offset > 0 {
 		params.Set("offset", strconv.Itoa(offset))
@@ -194,7 +385,7 @@ func (c *donatelyClient) ListPeople(account donately.Account, offset, limit int)
 		params.Set("limit", strconv.Itoa(limit))
 	}
 
-	resp, err := c.makeRequest(http.MethodGet, "/people?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/people?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -207,13 +398,13 @@ func (c *donatelyClient) ListPeople(account donately.Account, offset, limit int)
 	return people, nil
 }
 
-func (c *donatelyClient) FindPerson(id string, account donately.Account) (donately.Person, error) {
+func (c *donatelyClient) FindPerson(ctx context.Context, id string, account donately.Account) (donately.Person, error) {
 	endpoint := fmt.Sprintf("/people/%s", url.PathEscape(id))
 
 	params := url.Values{}
 	params.Set("account_id", account.ID)
 
-	resp, err := c.makeRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
 	if err != nil {
 		return donately.Person{}, err
 	}
@@ -226,8 +417,8 @@ func (c *donatelyClient) FindPerson(id string, account donately.Account) (donate
 	return person, nil
 }
 
-func (c *donatelyClient) Me() (donately.Person, error) {
-	resp, err := c.makeRequest(http.MethodGet, "/me", nil)
+func (c *donatelyClient) Me(ctx context.Context) (donately.Person, error) {
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/me", nil)
 	if err != nil {
 		return donately.Person{}, err
 	}
@@ -240,7 +431,7 @@ func (c *donatelyClient) Me() (donately.Person, error) {
 	return person, nil
 }
 
-func (c *donatelyClient) SavePerson(person donately.Person) (donately.Person, error) {
+func (c *donatelyClient) SavePerson(ctx context.Context, person donately.Person) (donately.Person, error) {
 	var endpoint string
 
 	if person.ID == "" {
@@ -253,44 +444,14 @@ func (c *donatelyClient) SavePerson(person donately.Person) (donately.Person, er
 		return donately.Person{}, errors.New("missing account information")
 	}
 
-	accountId := person.Accounts[0].ID
-
-	formData := url.Values{}
-
-	formData.Set("account_id", accountId)
-
-	if person.FirstName != "" {
-		formData.Set("first_name", person.FirstName)
-	}
-	if person.LastName != "" {
-		formData.Set("last_name", person.LastName)
-	}
-	if person.Email != "" {
-		formData.Set("email", person.Email)
-	}
-	if person.PhoneNumber != "" {
-		formData.Set("phone_number", person.PhoneNumber)
-	}
-	if person.StreetAddress != "" {
-		formData.Set("street_address", person.StreetAddress)
-	}
-	if person.StreetAddress2 != "" {
-		formData.Set("street_address_2", person.StreetAddress2)
-	}
-	if person.City != "" {
-		formData.Set("city", person.City)
-	}
-	if person.State != "" {
-		formData.Set("state", person.State)
-	}
-	if person.ZipCode != "" {
-		formData.Set("zip_code", person.ZipCode)
-	}
-	if person.Country != "" {
-		formData.Set("country", person.Country)
+	formData, err := EncodeForm(person)
+	if err != nil {
+		return donately.Person{}, fmt.Errorf("failed to encode person: %w", err)
 	}
 
-	resp, err := c.makeRequestWithContentType(http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
+	formData.Set("account_id", person.Accounts[0].ID)
+
+	resp, err := c.makeRequestWithContentType(ctx, http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
 	if err != nil {
 		return donately.Person{}, err
 	}
@@ -303,9 +464,10 @@ func (c *donatelyClient) SavePerson(person donately.Person) (donately.Person, er
 	return savedPerson, nil
 }
 
-func (c *donatelyClient) ListDonations(account donately.Account, offset, limit int) ([]donately.Donation, error) {
+func (c *donatelyClient) ListDonations(ctx context.Context, account donately.Account, filter DonationFilter, offset, limit int) ([]donately.Donation, error) {
 	params := url.Values{}
 	params.Set("account_id", account.ID)
+	filter.apply(params)
 
 	if offset > 0 {
 		params.Set("offset", strconv.Itoa(offset))
@@ -315,7 +477,7 @@ func (c *donatelyClient) ListDonations(account donately.Account, offset, limit i
 		params.Set("limit", strconv.Itoa(limit))
 	}
 
-	resp, err := c.makeRequest(http.MethodGet, "/donations?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/donations?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -328,8 +490,8 @@ func (c *donatelyClient) ListDonations(account donately.Account, offset, limit i
 	return donations, nil
 }
 
-func (c *donatelyClient) ListMyDonations() ([]donately.Donation, error) {
-	resp, err := c.makeRequest(http.MethodGet, "/me/donations", nil)
+func (c *donatelyClient) ListMyDonations(ctx context.Context) ([]donately.Donation, error) {
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/me/donations", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -342,12 +504,12 @@ func (c *donatelyClient) ListMyDonations() ([]donately.Donation, error) {
 	return donations, nil
 }
 
-func (c *donatelyClient) FindDonation(id string, account donately.Account) (donately.Donation, error) {
+func (c *donatelyClient) FindDonation(ctx context.Context, id string, account donately.Account) (donately.Donation, error) {
 	params := url.Values{}
 	params.Set("account_id", account.ID)
 
 	endpoint := fmt.Sprintf("/donations/%s", url.PathEscape(id))
-	resp, err := c.makeRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
 	if err != nil {
 		return donately.Donation{}, err
 	}
@@ -360,7 +522,7 @@ func (c *donatelyClient) FindDonation(id string, account donately.Account) (dona
 	return donation, nil
 }
 
-func (c *donatelyClient) SaveDonation(donation donately.Donation) (donately.Donation, error) {
+func (c *donatelyClient) SaveDonation(ctx context.Context, donation donately.Donation) (donately.Donation, error) {
 	var endpoint string
 
 	if donation.ID == "" {
@@ -373,54 +535,61 @@ func (c *donatelyClient) SaveDonation(donation donately.Donation) (donately.Dona
 		return donately.Donation{}, errors.New("missing account information")
 	}
 
-	params := url.Values{}
-	params.Set("account_id", donation.Account.ID)
-
-	if donation.AmountInCents > 0 {
-		params.Set("amount_in_cents", fmt.Sprintf("%d", donation.AmountInCents))
-	}
-	if donation.DonationType != "" {
-		params.Set("donation_type", donation.DonationType)
-	}
-	if donation.Campaign.ID != "" {
-		params.Set("campaign_id", donation.Campaign.ID)
+	if donation.DonationType != "" && !donation.DonationType.Valid() {
+		return donately.Donation{}, fmt.Errorf("%w: unknown donation type %q", ErrValidation, donation.DonationType)
 	}
-	if donation.Person.Email != "" {
-		params.Set("email", donation.Person.Email)
-	}
-	if donation.Comment != "" {
-		params.Set("comment", donation.Comment)
-	}
-	if donation.Anonymous {
-		params.Set("anonymous", "true")
-	}
-	if donation.OnBehalfOf != "" {
-		params.Set("on_behalf_of", donation.OnBehalfOf)
-	}
-	if donation.Status != "" {
-		params.Set("status", donation.Status)
+
+	if donation.Status != "" && !donation.Status.Valid() {
+		return donately.Donation{}, fmt.Errorf("%w: unknown donation status %q", ErrValidation, donation.Status)
 	}
 
-	if len(params) > 0 {
-		endpoint += "?" + params.Encode()
+	formData, err := EncodeForm(donation)
+	if err != nil {
+		return donately.Donation{}, fmt.Errorf("failed to encode donation: %w", err)
 	}
 
-	resp, err := c.makeRequest(http.MethodPost, endpoint, nil)
+	payload := []byte(formData.Encode())
+	key := donation.IdempotencyKey()
 
-	re, ok := err.(retryable)
-	if ok && re.CanRetry() {
-		operation := func() (*APIResponse, error) {
-			return c.makeRequest(http.MethodPost, endpoint, nil)
+	// A create can succeed even though Donately answers "retry later", so
+	// before sending it again look for the donation the last attempt made.
+	var existing *donately.Donation
+
+	resp, err := c.retryPolicy.do(ctx, c.logger, http.MethodPost, func(attempt int) (*APIResponse, error) {
+		if attempt > 1 && donation.ID == "" && key != "" {
+			found, ok, err := c.findDonationByIdempotencyKey(ctx, donation, key)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				c.logger.Info("donation was created by an earlier attempt", slog.String("idempotency_key", key), slog.String("donation_id", found.ID))
+				existing = &found
+				return &APIResponse{}, nil
+			}
 		}
-		resp, err = backoff.Retry(context.TODO(), operation, backoff.WithBackOff(backoff.NewExponentialBackOff()))
-		if err != nil {
-			return donately.Donation{}, err
 
+		resp, err := c.doRequest(ctx, attempt, http.MethodPost, endpoint, payload, "application/x-www-form-urlencoded")
+
+		// Unlike other writes, a create carrying a key can safely be retried
+		// after an ambiguous "retry later", since the next attempt checks for
+		// it first.
+		var re retryableError
+		if donation.ID == "" && key != "" && errors.Is(err, ErrRateLimited) && errors.As(err, &re) {
+			re.rejected = true
+			return nil, re
 		}
-	} else {
+
+		return resp, err
+	})
+	if err != nil {
 		return donately.Donation{}, err
 	}
 
+	if existing != nil {
+		return *existing, nil
+	}
+
 	var savedDonation donately.Donation
 	if err := json.Unmarshal(resp.Data, &savedDonation); err != nil {
 		return donately.Donation{}, fmt.Errorf("failed to unmarshal saved donation: %w", err)
@@ -429,7 +598,23 @@ func (c *donatelyClient) SaveDonation(donation donately.Donation) (donately.Dona
 	return savedDonation, nil
 }
 
-func (c *donatelyClient) RefundDonation(donation donately.Donation, reason string) error {
+func (c *donatelyClient) findDonationByIdempotencyKey(ctx context.Context, donation donately.Donation, key string) (donately.Donation, bool, error) {
+	filter := DonationFilter{CampaignID: donation.Campaign.ID, PersonID: donation.Person.ID}
+
+	for candidate, err := range c.Donations(ctx, donation.Account, filter) {
+		if err != nil {
+			return donately.Donation{}, false, err
+		}
+
+		if candidate.IdempotencyKey() == key {
+			return candidate, true, nil
+		}
+	}
+
+	return donately.Donation{}, false, nil
+}
+
+func (c *donatelyClient) RefundDonation(ctx context.Context, donation donately.Donation, reason string) error {
 	endpoint := fmt.Sprintf("/donations/%s/refund", url.PathEscape(donation.ID))
 
 	if donation.Account.ID == "" {
@@ -440,22 +625,31 @@ func (c *donatelyClient) RefundDonation(donation donately.Donation, reason strin
 	formData.Set("account_id", donation.Account.ID)
 	formData.Set("refund_reason", reason)
 
-	_, err := c.makeRequest(http.MethodPost, endpoint, formData)
+	_, err := c.makeRequestWithContentType(ctx, http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
 	return err
 }
 
-func (c *donatelyClient) SendDonationReceipt(donation donately.Donation) error {
+func (c *donatelyClient) SendDonationReceipt(ctx context.Context, donation donately.Donation) error {
 	endpoint := fmt.Sprintf("/donations/%s/receipt", url.PathEscape(donation.ID))
-	_, err := c.makeRequest(http.MethodPost, endpoint, nil)
+	_, err := c.makeRequest(ctx, http.MethodPost, endpoint, nil)
 	return err
 }
 
 // Subscriptions operations
-func (c *donatelyClient) ListSubscriptions(account donately.Account) ([]donately.Subscription, error) {
+func (c *donatelyClient) ListSubscriptions(ctx context.Context, account donately.Account, filter SubscriptionFilter, offset, limit int) ([]donately.Subscription, error) {
 	params := url.Values{}
 	params.Set("account_id", account.ID)
+	filter.apply(params)
+
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
 
-	resp, err := c.makeRequest(http.MethodGet, "/subscriptions?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/subscriptions?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -468,8 +662,8 @@ func (c *donatelyClient) ListSubscriptions(account donately.Account) ([]donately
 	return subscriptions, nil
 }
 
-func (c *donatelyClient) ListMySubscriptions() ([]donately.Subscription, error) {
-	resp, err := c.makeRequest(http.MethodGet, "/me/subscriptions", nil)
+func (c *donatelyClient) ListMySubscriptions(ctx context.Context) ([]donately.Subscription, error) {
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/me/subscriptions", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -482,13 +676,13 @@ func (c *donatelyClient) ListMySubscriptions() ([]donately.Subscription, error)
 	return subscriptions, nil
 }
 
-func (c *donatelyClient) FindSubscription(id string, account donately.Account) (donately.Subscription, error) {
+func (c *donatelyClient) FindSubscription(ctx context.Context, id string, account donately.Account) (donately.Subscription, error) {
 	endpoint := fmt.Sprintf("/subscriptions/%s", url.PathEscape(id))
 
 	params := url.Values{}
 	params.Set("account_id", account.ID)
 
-	resp, err := c.makeRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
 	if err != nil {
 		return donately.Subscription{}, err
 	}
@@ -501,7 +695,7 @@ func (c *donatelyClient) FindSubscription(id string, account donately.Account) (
 	return subscription, nil
 }
 
-func (c *donatelyClient) SaveSubscription(subscription donately.Subscription) (donately.Subscription, error) {
+func (c *donatelyClient) SaveSubscription(ctx context.Context, subscription donately.Subscription) (donately.Subscription, error) {
 	var endpoint string
 
 	if subscription.ID == "" {
@@ -510,7 +704,30 @@ func (c *donatelyClient) SaveSubscription(subscription donately.Subscription) (d
 		endpoint = fmt.Sprintf("/subscriptions/%s", url.PathEscape(subscription.ID))
 	}
 
-	resp, err := c.makeRequest(http.MethodPost, endpoint, subscription)
+	formData, err := EncodeForm(subscription)
+	if err != nil {
+		return donately.Subscription{}, fmt.Errorf("failed to encode subscription: %w", err)
+	}
+
+	return c.postSubscription(ctx, endpoint, formData)
+}
+
+// PatchSubscription sends only the fields that changed between original and updated.
+func (c *donatelyClient) PatchSubscription(ctx context.Context, original, updated donately.Subscription) (donately.Subscription, error) {
+	if updated.ID == "" {
+		return donately.Subscription{}, errors.New("cannot patch a subscription without an id")
+	}
+
+	formData, err := EncodePatch(original, updated)
+	if err != nil {
+		return donately.Subscription{}, fmt.Errorf("failed to encode subscription patch: %w", err)
+	}
+
+	return c.postSubscription(ctx, fmt.Sprintf("/subscriptions/%s", url.PathEscape(updated.ID)), formData)
+}
+
+func (c *donatelyClient) postSubscription(ctx context.Context, endpoint string, formData url.Values) (donately.Subscription, error) {
+	resp, err := c.makeRequestWithContentType(ctx, http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
 	if err != nil {
 		return donately.Subscription{}, err
 	}
@@ -524,11 +741,20 @@ func (c *donatelyClient) SaveSubscription(subscription donately.Subscription) (d
 }
 
 // Campaigns operations
-func (c *donatelyClient) ListCampaigns(account donately.Account) ([]donately.Campaign, error) {
+func (c *donatelyClient) ListCampaigns(ctx context.Context, account donately.Account, filter CampaignFilter, offset, limit int) ([]donately.Campaign, error) {
 	params := url.Values{}
 	params.Set("account_id", account.ID)
+	filter.apply(params)
 
-	resp, err := c.makeRequest(http.MethodGet, "/campaigns?"+params.Encode(), nil)
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/campaigns?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -541,13 +767,13 @@ func (c *donatelyClient) ListCampaigns(account donately.Account) ([]donately.Cam
 	return campaigns, nil
 }
 
-func (c *donatelyClient) FindCampaign(id string, account donately.Account) (donately.Campaign, error) {
+func (c *donatelyClient) FindCampaign(ctx context.Context, id string, account donately.Account) (donately.Campaign, error) {
 	endpoint := fmt.Sprintf("/campaigns/%s", url.PathEscape(id))
 
 	params := url.Values{}
 	params.Add("account_id", account.ID)
 
-	resp, err := c.makeRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
 	if err != nil {
 		return donately.Campaign{}, err
 	}
@@ -560,7 +786,7 @@ func (c *donatelyClient) FindCampaign(id string, account donately.Account) (dona
 	return campaign, nil
 }
 
-func (c *donatelyClient) SaveCampaign(campaign donately.Campaign) (donately.Campaign, error) {
+func (c *donatelyClient) SaveCampaign(ctx context.Context, campaign donately.Campaign) (donately.Campaign, error) {
 	var endpoint string
 
 	if campaign.ID == "" {
@@ -569,7 +795,31 @@ func (c *donatelyClient) SaveCampaign(campaign donately.Campaign) (donately.Camp
 		endpoint = fmt.Sprintf("/campaigns/%s", url.PathEscape(campaign.ID))
 	}
 
-	resp, err := c.makeRequest(http.MethodPost, endpoint, campaign)
+	formData, err := EncodeForm(campaign)
+	if err != nil {
+		return donately.Campaign{}, fmt.Errorf("failed to encode campaign: %w", err)
+	}
+
+	return c.postCampaign(ctx, endpoint, formData)
+}
+
+// PatchCampaign sends only the fields that changed between original and
+// updated, so e.g. editing a goal leaves every other field untouched.
+func (c *donatelyClient) PatchCampaign(ctx context.Context, original, updated donately.Campaign) (donately.Campaign, error) {
+	if updated.ID == "" {
+		return donately.Campaign{}, errors.New("cannot patch a campaign without an id")
+	}
+
+	formData, err := EncodePatch(original, updated)
+	if err != nil {
+		return donately.Campaign{}, fmt.Errorf("failed to encode campaign patch: %w", err)
+	}
+
+	return c.postCampaign(ctx, fmt.Sprintf("/campaigns/%s", url.PathEscape(updated.ID)), formData)
+}
+
+func (c *donatelyClient) postCampaign(ctx context.Context, endpoint string, formData url.Values) (donately.Campaign, error) {
+	resp, err := c.makeRequestWithContentType(ctx, http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
 	if err != nil {
 		return donately.Campaign{}, err
 	}
@@ -582,8 +832,85 @@ func (c *donatelyClient) SaveCampaign(campaign donately.Campaign) (donately.Camp
 	return savedCampaign, nil
 }
 
-func (c *donatelyClient) DeleteCampaign(campaign donately.Campaign) error {
+func (c *donatelyClient) DeleteCampaign(ctx context.Context, campaign donately.Campaign) error {
 	endpoint := fmt.Sprintf("/campaigns/%s", url.PathEscape(campaign.ID))
-	_, err := c.makeRequest(http.MethodDelete, endpoint, nil)
+	_, err := c.makeRequest(ctx, http.MethodDelete, endpoint, nil)
 	return err
 }
+
+// Fundraisers operations
+func (c *donatelyClient) ListFundraisers(ctx context.Context, account donately.Account, filter FundraiserFilter, offset, limit int) ([]donately.Fundraiser, error) {
+	params := url.Values{}
+	params.Set("account_id", account.ID)
+	filter.apply(params)
+
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	resp, err := c.makeRequest(ctx, http.MethodGet, "/fundraisers?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var fundraisers []donately.Fundraiser
+	if err := json.Unmarshal(resp.Data, &fundraisers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fundraisers: %w", err)
+	}
+
+	return fundraisers, nil
+}
+
+func (c *donatelyClient) FindFundraiser(ctx context.Context, id string, account donately.Account) (donately.Fundraiser, error) {
+	endpoint := fmt.Sprintf("/fundraisers/%s", url.PathEscape(id))
+
+	params := url.Values{}
+	params.Set("account_id", account.ID)
+
+	resp, err := c.makeRequest(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return donately.Fundraiser{}, err
+	}
+
+	var fundraiser donately.Fundraiser
+	if err := json.Unmarshal(resp.Data, &fundraiser); err != nil {
+		return donately.Fundraiser{}, fmt.Errorf("failed to unmarshal fundraiser: %w", err)
+	}
+
+	return fundraiser, nil
+}
+
+func (c *donatelyClient) SaveFundraiser(ctx context.Context, fundraiser donately.Fundraiser) (donately.Fundraiser, error) {
+	var endpoint string
+
+	if fundraiser.ID == "" {
+		endpoint = "/fundraisers"
+	} else {
+		endpoint = fmt.Sprintf("/fundraisers/%s", url.PathEscape(fundraiser.ID))
+	}
+
+	if fundraiser.Account.ID == "" {
+		return donately.Fundraiser{}, errors.New("missing account information")
+	}
+
+	formData, err := EncodeForm(fundraiser)
+	if err != nil {
+		return donately.Fundraiser{}, fmt.Errorf("failed to encode fundraiser: %w", err)
+	}
+
+	resp, err := c.makeRequestWithContentType(ctx, http.MethodPost, endpoint, formData, "application/x-www-form-urlencoded")
+	if err != nil {
+		return donately.Fundraiser{}, err
+	}
+
+	var savedFundraiser donately.Fundraiser
+	if err := json.Unmarshal(resp.Data, &savedFundraiser); err != nil {
+		return donately.Fundraiser{}, fmt.Errorf("failed to unmarshal saved fundraiser: %w", err)
+	}
+
+	return savedFundraiser, nil
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrNotFound     = errors.New("donately: not found")
+	ErrUnauthorized = errors.New("donately: unauthorized")
+	ErrValidation   = errors.New("donately: validation failed")
+	ErrRateLimited  = errors.New("donately: rate limited")
+)
+
+// APIError is returned whenever Donately answers with an error envelope or a
+// non-2xx status. Use errors.Is with the Err* sentinels to classify it.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Code       string
+	Type       string
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	if e.Code != "" || e.Type != "" {
+		fmt.Fprintf(&b, "API error: %s - (%s) %s", e.Code, e.Type, e.Message)
+	} else {
+		fmt.Fprintf(&b, "HTTP error: %d", e.StatusCode)
+		if e.Message != "" {
+			fmt.Fprintf(&b, " %s", e.Message)
+		}
+	}
+
+	fmt.Fprintf(&b, " [%s %s", e.Method, e.Endpoint)
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, " status=%d", e.StatusCode)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " request_id=%s", e.RequestID)
+	}
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// Reason is a stable description of the failure, suitable for grouping
+// errors without request-specific noise like request IDs.
+func (e *APIError) Reason() string {
+	if e.Code != "" || e.Message != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.codeContains("not_found", "not found")
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+			e.codeContains("unauthorized", "authentication", "forbidden")
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity ||
+			e.codeContains("invalid", "validation", "missing")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.codeContains("rate_limit", "retry later")
+	}
+
+	return false
+}
+
+func (e *APIError) codeContains(fragments ...string) bool {
+	haystack := strings.ToLower(e.Code + " " + e.Type + " " + e.Message)
+
+	for _, fragment := range fragments {
+		if strings.Contains(haystack, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FailureReason describes err in a way that groups identical failures together.
+func FailureReason(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Reason()
+	}
+
+	return err.Error()
+}
+
+// FailureClass buckets err into one of a fixed set of classes (not_found,
+// validation, rate_limited, unauthorized or other), for places like metric
+// labels that can't take free text.
+func FailureClass(err error) string {
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrValidation):
+		return "validation"
+	default:
+		return "other"
+	}
+}
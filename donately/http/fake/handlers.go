@@ -0,0 +1,984 @@
+package fake
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+// params merges the query string with a form or JSON body, since the client
+// sends parameters all three ways depending on the endpoint.
+func params(r *http.Request) (url.Values, error) {
+	values := r.URL.Query()
+
+	if r.Body == nil {
+		return values, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return values, nil
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil, err
+		}
+		for key, vs := range form {
+			values[key] = vs
+		}
+	default:
+		var body map[string]any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return values, nil
+		}
+		for key, v := range body {
+			switch v := v.(type) {
+			case string:
+				values.Set(key, v)
+			case float64:
+				values.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+			case bool:
+				values.Set(key, strconv.FormatBool(v))
+			case []any:
+				for _, item := range v {
+					if str, ok := item.(string); ok {
+						values.Add(key, str)
+					}
+				}
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func page[T any](r *http.Request, records []T) []T {
+	query := r.URL.Query()
+
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+
+	if offset < 0 || offset >= len(records) {
+		return []T{}
+	}
+
+	end := min(offset+limit, len(records))
+
+	return append([]T(nil), records[offset:end]...)
+}
+
+func filter[T any](records []T, keep func(T) bool) []T {
+	kept := []T{}
+	for _, record := range records {
+		if keep(record) {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
+
+func inAccount(accountID string, accounts ...donately.Account) bool {
+	if accountID == "" {
+		return true
+	}
+
+	for _, account := range accounts {
+		if account.ID == accountID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches reports whether an optional equality filter is absent or satisfied.
+func matches(query url.Values, key, value string) bool {
+	want := query.Get(key)
+	return want == "" || strings.EqualFold(want, value)
+}
+
+// within applies the optional <prefix>_after and <prefix>_before unix bounds.
+func within(query url.Values, prefix string, value donately.UnixTime) bool {
+	if after, err := strconv.ParseInt(query.Get(prefix+"_after"), 10, 64); err == nil && int64(value) < after {
+		return false
+	}
+	if before, err := strconv.ParseInt(query.Get(prefix+"_before"), 10, 64); err == nil && int64(value) > before {
+		return false
+	}
+	return true
+}
+
+func now() donately.UnixTime {
+	return donately.UnixTimeOf(time.Now())
+}
+
+// Accounts
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	accounts := page(r, s.accounts)
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, accounts)
+}
+
+func (s *Server) findAccount(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	account, ok := s.account(r.PathValue("id"))
+	s.mu.Unlock()
+
+	if !ok {
+		s.notFound(w, "account", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, account)
+}
+
+// account looks an account up by ID or subdomain. Call with s.mu held.
+func (s *Server) account(id string) (donately.Account, bool) {
+	for _, account := range s.accounts {
+		if account.ID == id || (account.Subdomain != "" && account.Subdomain == id) {
+			return account, true
+		}
+	}
+
+	return donately.Account{}, false
+}
+
+// People
+
+func (s *Server) findMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	me := s.me
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, me)
+}
+
+func (s *Server) listPeople(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+
+	query := r.URL.Query()
+	email := query.Get("email")
+	search := strings.ToLower(query.Get("search"))
+
+	s.mu.Lock()
+	people := filter(s.people, func(p donately.Person) bool {
+		if !inAccount(accountID, p.Accounts...) {
+			return false
+		}
+		if email != "" && !strings.EqualFold(p.Email, email) {
+			return false
+		}
+		if search != "" && !strings.Contains(strings.ToLower(p.FirstName+" "+p.LastName+" "+p.Email), search) {
+			return false
+		}
+		return true
+	})
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, page(r, people))
+}
+
+func (s *Server) findPerson(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.personIndex(r.PathValue("id"))
+	var person donately.Person
+	if i >= 0 {
+		person = s.people[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "person", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, person)
+}
+
+// personIndex must be called with s.mu held.
+func (s *Server) personIndex(id string) int {
+	for i, person := range s.people {
+		if person.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// personByEmail must be called with s.mu held.
+func (s *Server) personByEmail(email string) int {
+	for i, person := range s.people {
+		if strings.EqualFold(person.Email, email) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) savePerson(w http.ResponseWriter, r *http.Request) {
+	values, err := params(r)
+	if err != nil {
+		s.invalid(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.account(values.Get("account_id"))
+	if !ok {
+		s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_account", "account_id is missing or unknown")
+		return
+	}
+
+	var person donately.Person
+	i := -1
+
+	if id := r.PathValue("id"); id != "" {
+		if i = s.personIndex(id); i < 0 {
+			s.notFound(w, "person", id)
+			return
+		}
+		person = s.people[i]
+	} else {
+		if values.Get("email") == "" || !strings.Contains(values.Get("email"), "@") {
+			s.invalid(w, "invalid email")
+			return
+		}
+
+		if s.personByEmail(values.Get("email")) >= 0 {
+			s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "duplicate_email", "a person with that email already exists")
+			return
+		}
+
+		person = donately.Person{ID: s.newID("person"), Created: now()}
+	}
+
+	for field, target := range map[string]*string{
+		"email":            &person.Email,
+		"first_name":       &person.FirstName,
+		"last_name":        &person.LastName,
+		"phone_number":     &person.PhoneNumber,
+		"street_address":   &person.StreetAddress,
+		"street_address_2": &person.StreetAddress2,
+		"city":             &person.City,
+		"state":            &person.State,
+		"zip_code":         &person.ZipCode,
+		"country":          &person.Country,
+	} {
+		if values.Has(field) {
+			*target = values.Get(field)
+		}
+	}
+
+	if !inAccount(account.ID, person.Accounts...) {
+		person.Accounts = append(person.Accounts, account)
+	}
+	person.Updated = now()
+
+	if i >= 0 {
+		s.people[i] = person
+	} else {
+		s.people = append(s.people, person)
+	}
+
+	s.writeData(w, http.StatusOK, person)
+}
+
+// Donations
+
+func (s *Server) listDonations(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	donations := filter(s.donations, func(d donately.Donation) bool {
+		return inAccount(accountID, d.Account) &&
+			matches(query, "campaign_id", d.Campaign.ID) &&
+			matches(query, "person_id", d.Person.ID) &&
+			matches(query, "status", string(d.Status)) &&
+			matches(query, "donation_type", string(d.DonationType)) &&
+			matches(query, "livemode", strconv.FormatBool(d.Livemode)) &&
+			within(query, "created", d.Created) &&
+			within(query, "updated", d.Updated)
+	})
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, page(r, donations))
+}
+
+func (s *Server) listMyDonations(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	meID := s.me.ID
+	donations := filter(s.donations, func(d donately.Donation) bool { return d.Person.ID == meID })
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, donations)
+}
+
+// donationIndex must be called with s.mu held.
+func (s *Server) donationIndex(id string) int {
+	for i, donation := range s.donations {
+		if donation.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) findDonation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.donationIndex(r.PathValue("id"))
+	var donation donately.Donation
+	if i >= 0 {
+		donation = s.donations[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "donation", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, donation)
+}
+
+func (s *Server) saveDonation(w http.ResponseWriter, r *http.Request) {
+	values, err := params(r)
+	if err != nil {
+		s.invalid(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.account(values.Get("account_id"))
+	if !ok {
+		s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_account", "account_id is missing or unknown")
+		return
+	}
+
+	var donation donately.Donation
+	i := -1
+
+	if id := r.PathValue("id"); id != "" {
+		if i = s.donationIndex(id); i < 0 {
+			s.notFound(w, "donation", id)
+			return
+		}
+		donation = s.donations[i]
+	} else {
+		donation = donately.Donation{
+			ID:           s.newID("donation"),
+			Account:      account,
+			Currency:     account.Currency,
+			Status:       donately.DonationProcessed,
+			DonationType: donately.DonationTypeCard,
+			Livemode:     true,
+			Created:      now(),
+			DonationDate: now(),
+		}
+	}
+
+	if raw := values.Get("amount_in_cents"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || amount <= 0 {
+			s.invalid(w, "amount_in_cents must be a positive integer")
+			return
+		}
+		donation.AmountInCents = amount
+	}
+
+	if i < 0 && donation.AmountInCents <= 0 {
+		s.invalid(w, "amount_in_cents is required")
+		return
+	}
+
+	if values.Has("donation_type") {
+		donation.DonationType = donately.DonationType(values.Get("donation_type"))
+	}
+	if values.Has("status") {
+		donation.Status = donately.DonationStatus(values.Get("status"))
+	}
+	if values.Has("comment") {
+		donation.Comment = values.Get("comment")
+	}
+	if values.Has("on_behalf_of") {
+		donation.OnBehalfOf = values.Get("on_behalf_of")
+	}
+	if values.Has("anonymous") {
+		donation.Anonymous = values.Get("anonymous") == "true"
+	}
+	if values.Has("tracking_codes") {
+		donation.TrackingCodes = values.Get("tracking_codes")
+	}
+
+	if campaignID := values.Get("campaign_id"); campaignID != "" {
+		c := s.campaignIndex(campaignID)
+		if c < 0 {
+			s.notFound(w, "campaign", campaignID)
+			return
+		}
+		donation.Campaign = s.campaigns[c]
+	}
+
+	if email := values.Get("email"); email != "" {
+		p := s.personByEmail(email)
+		if p < 0 {
+			s.people = append(s.people, donately.Person{ID: s.newID("person"), Email: email, Accounts: []donately.Account{account}, Created: now(), Updated: now()})
+			p = len(s.people) - 1
+		}
+		donation.Person = s.people[p]
+	} else if personID := values.Get("person_id"); personID != "" {
+		p := s.personIndex(personID)
+		if p < 0 {
+			s.notFound(w, "person", personID)
+			return
+		}
+		donation.Person = s.people[p]
+	}
+
+	if fundraiserID := values.Get("fundraiser_id"); fundraiserID != "" {
+		f := s.fundraiserIndex(fundraiserID)
+		if f < 0 {
+			s.notFound(w, "fundraiser", fundraiserID)
+			return
+		}
+		fundraiser := s.fundraisers[f]
+		donation.Fundraiser = &fundraiser
+	}
+
+	donation.Updated = now()
+
+	if i >= 0 {
+		s.donations[i] = donation
+	} else {
+		s.donations = append(s.donations, donation)
+		s.creditCampaign(donation.Campaign.ID, donation.AmountInCents)
+		if donation.Fundraiser != nil {
+			s.creditFundraiser(donation.Fundraiser.ID, donation.AmountInCents)
+		}
+	}
+
+	s.writeData(w, http.StatusOK, donation)
+}
+
+// creditCampaign must be called with s.mu held.
+func (s *Server) creditCampaign(campaignID string, amountInCents int64) {
+	c := s.campaignIndex(campaignID)
+	if c < 0 {
+		return
+	}
+
+	campaign := &s.campaigns[c]
+	campaign.AmountRaisedInCents += amountInCents
+	if amountInCents > 0 {
+		campaign.DonorsCount++
+	}
+	if campaign.GoalInCents > 0 {
+		campaign.PercentFunded = float64(campaign.AmountRaisedInCents) / float64(campaign.GoalInCents) * 100
+	}
+}
+
+func (s *Server) refundDonation(w http.ResponseWriter, r *http.Request) {
+	values, err := params(r)
+	if err != nil {
+		s.invalid(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.donationIndex(r.PathValue("id"))
+	if i < 0 {
+		s.notFound(w, "donation", r.PathValue("id"))
+		return
+	}
+
+	donation := &s.donations[i]
+	if donation.Refunded != nil && *donation.Refunded {
+		s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "already_refunded", "donation has already been refunded")
+		return
+	}
+
+	refunded := true
+	donation.Refunded = &refunded
+	donation.Status = donately.DonationRefunded
+	donation.Refunds = append(donation.Refunds, donately.Refund{
+		ID:            s.newID("refund"),
+		AmountInCents: donation.AmountInCents,
+		Currency:      donation.Currency,
+		Status:        donately.RefundSucceeded,
+		Reason:        values.Get("refund_reason"),
+		Created:       now(),
+	})
+	donation.Updated = now()
+	s.creditCampaign(donation.Campaign.ID, -donation.AmountInCents)
+	if donation.Fundraiser != nil {
+		s.creditFundraiser(donation.Fundraiser.ID, -donation.AmountInCents)
+	}
+
+	s.writeData(w, http.StatusOK, *donation)
+}
+
+func (s *Server) sendReceipt(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.donationIndex(r.PathValue("id"))
+	var donation donately.Donation
+	if i >= 0 {
+		donation = s.donations[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "donation", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, donation)
+}
+
+// Subscriptions
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	subscriptions := filter(s.subscriptions, func(sub donately.Subscription) bool {
+		return inAccount(accountID, sub.Account) &&
+			matches(query, "status", sub.Status) &&
+			matches(query, "campaign_id", sub.Campaign.ID) &&
+			matches(query, "person_id", sub.Person.ID) &&
+			matches(query, "recurring_frequency", string(sub.RecurringFrequency))
+	})
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, page(r, subscriptions))
+}
+
+func (s *Server) listMySubscriptions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	meID := s.me.ID
+	subscriptions := filter(s.subscriptions, func(sub donately.Subscription) bool { return sub.Person.ID == meID })
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, subscriptions)
+}
+
+// subscriptionIndex must be called with s.mu held.
+func (s *Server) subscriptionIndex(id string) int {
+	for i, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) findSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.subscriptionIndex(r.PathValue("id"))
+	var subscription donately.Subscription
+	if i >= 0 {
+		subscription = s.subscriptions[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "subscription", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, subscription)
+}
+
+func (s *Server) saveSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subscription donately.Subscription
+	i := -1
+
+	if id := r.PathValue("id"); id != "" {
+		if i = s.subscriptionIndex(id); i < 0 {
+			s.notFound(w, "subscription", id)
+			return
+		}
+		subscription = s.subscriptions[i]
+	}
+
+	values, ok := decodeInto(r, &subscription)
+	if !ok {
+		s.invalid(w, "malformed subscription")
+		return
+	}
+
+	if accountID := values.Get("account_id"); accountID != "" {
+		account, ok := s.account(accountID)
+		if !ok {
+			s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_account", "account_id is unknown")
+			return
+		}
+		subscription.Account = account
+	}
+
+	if campaignID := values.Get("campaign_id"); campaignID != "" {
+		c := s.campaignIndex(campaignID)
+		if c < 0 {
+			s.notFound(w, "campaign", campaignID)
+			return
+		}
+		subscription.Campaign = s.campaigns[c]
+	}
+
+	if email := values.Get("email"); email != "" {
+		p := s.personByEmail(email)
+		if p < 0 {
+			s.notFound(w, "person", email)
+			return
+		}
+		subscription.Person = s.people[p]
+	}
+
+	if fundraiserID := values.Get("fundraiser_id"); fundraiserID != "" {
+		f := s.fundraiserIndex(fundraiserID)
+		if f < 0 {
+			s.notFound(w, "fundraiser", fundraiserID)
+			return
+		}
+		fundraiser := s.fundraisers[f]
+		subscription.Fundraiser = &fundraiser
+	}
+
+	if i < 0 {
+		subscription.ID = s.newID("subscription")
+		subscription.Created = now()
+	}
+	subscription.Updated = now()
+
+	if i >= 0 {
+		s.subscriptions[i] = subscription
+	} else {
+		s.subscriptions = append(s.subscriptions, subscription)
+	}
+
+	s.writeData(w, http.StatusOK, subscription)
+}
+
+// Campaigns
+
+func (s *Server) listCampaigns(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	campaigns := filter(s.campaigns, func(c donately.Campaign) bool {
+		return inAccount(accountID, c.Account) && matches(query, "status", c.Status)
+	})
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, page(r, campaigns))
+}
+
+// campaignIndex must be called with s.mu held.
+func (s *Server) campaignIndex(id string) int {
+	for i, campaign := range s.campaigns {
+		if campaign.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) findCampaign(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.campaignIndex(r.PathValue("id"))
+	var campaign donately.Campaign
+	if i >= 0 {
+		campaign = s.campaigns[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "campaign", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, campaign)
+}
+
+func (s *Server) saveCampaign(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var campaign donately.Campaign
+	i := -1
+
+	if id := r.PathValue("id"); id != "" {
+		if i = s.campaignIndex(id); i < 0 {
+			s.notFound(w, "campaign", id)
+			return
+		}
+		campaign = s.campaigns[i]
+	}
+
+	values, ok := decodeInto(r, &campaign)
+	if !ok {
+		s.invalid(w, "malformed campaign")
+		return
+	}
+
+	if accountID := values.Get("account_id"); accountID != "" {
+		account, ok := s.account(accountID)
+		if !ok {
+			s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_account", "account_id is unknown")
+			return
+		}
+		campaign.Account = account
+	}
+
+	if i < 0 {
+		campaign.ID = s.newID("campaign")
+		campaign.Created = now()
+	}
+	campaign.Updated = now()
+
+	if i >= 0 {
+		s.campaigns[i] = campaign
+	} else {
+		s.campaigns = append(s.campaigns, campaign)
+	}
+
+	s.writeData(w, http.StatusOK, campaign)
+}
+
+func (s *Server) deleteCampaign(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.campaignIndex(r.PathValue("id"))
+	if i < 0 {
+		s.notFound(w, "campaign", r.PathValue("id"))
+		return
+	}
+
+	campaign := s.campaigns[i]
+	s.campaigns = append(s.campaigns[:i], s.campaigns[i+1:]...)
+
+	s.writeData(w, http.StatusOK, campaign)
+}
+
+// Fundraisers
+
+func (s *Server) listFundraisers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	accountID := query.Get("account_id")
+
+	s.mu.Lock()
+	fundraisers := filter(s.fundraisers, func(f donately.Fundraiser) bool {
+		return inAccount(accountID, f.Account) &&
+			matches(query, "campaign_id", f.Campaign.ID) &&
+			matches(query, "person_id", f.Person.ID) &&
+			matches(query, "status", f.Status)
+	})
+	s.mu.Unlock()
+
+	s.writeData(w, http.StatusOK, page(r, fundraisers))
+}
+
+// fundraiserIndex must be called with s.mu held.
+func (s *Server) fundraiserIndex(id string) int {
+	for i, fundraiser := range s.fundraisers {
+		if fundraiser.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) findFundraiser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	i := s.fundraiserIndex(r.PathValue("id"))
+	var fundraiser donately.Fundraiser
+	if i >= 0 {
+		fundraiser = s.fundraisers[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		s.notFound(w, "fundraiser", r.PathValue("id"))
+		return
+	}
+
+	s.writeData(w, http.StatusOK, fundraiser)
+}
+
+func (s *Server) saveFundraiser(w http.ResponseWriter, r *http.Request) {
+	values, err := params(r)
+	if err != nil {
+		s.invalid(w, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.account(values.Get("account_id"))
+	if !ok {
+		s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_account", "account_id is missing or unknown")
+		return
+	}
+
+	var fundraiser donately.Fundraiser
+	i := -1
+
+	if id := r.PathValue("id"); id != "" {
+		if i = s.fundraiserIndex(id); i < 0 {
+			s.notFound(w, "fundraiser", id)
+			return
+		}
+		fundraiser = s.fundraisers[i]
+	} else {
+		fundraiser = donately.Fundraiser{ID: s.newID("fundraiser"), Account: account, Status: "published", Created: now()}
+	}
+
+	if campaignID := values.Get("campaign_id"); campaignID != "" {
+		c := s.campaignIndex(campaignID)
+		if c < 0 {
+			s.notFound(w, "campaign", campaignID)
+			return
+		}
+		fundraiser.Campaign = s.campaigns[c]
+	}
+
+	if personID := values.Get("person_id"); personID != "" {
+		p := s.personIndex(personID)
+		if p < 0 {
+			s.notFound(w, "person", personID)
+			return
+		}
+		fundraiser.Person = s.people[p]
+	} else if email := values.Get("email"); email != "" {
+		p := s.personByEmail(email)
+		if p < 0 {
+			s.notFound(w, "person", email)
+			return
+		}
+		fundraiser.Person = s.people[p]
+	}
+
+	if values.Has("title") {
+		fundraiser.Title = values.Get("title")
+	}
+	if values.Has("description") {
+		description := values.Get("description")
+		fundraiser.Description = &description
+	}
+	if values.Has("content") {
+		content := values.Get("content")
+		fundraiser.Content = &content
+	}
+	if raw := values.Get("goal_in_cents"); raw != "" {
+		goal, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || goal < 0 {
+			s.invalid(w, "goal_in_cents must be a non-negative integer")
+			return
+		}
+		fundraiser.GoalInCents = goal
+	}
+
+	fundraiser.Updated = now()
+
+	if i >= 0 {
+		s.fundraisers[i] = fundraiser
+	} else {
+		s.fundraisers = append(s.fundraisers, fundraiser)
+	}
+
+	s.writeData(w, http.StatusOK, fundraiser)
+}
+
+// creditFundraiser must be called with s.mu held.
+func (s *Server) creditFundraiser(fundraiserID string, amountInCents int64) {
+	f := s.fundraiserIndex(fundraiserID)
+	if f < 0 {
+		return
+	}
+
+	fundraiser := &s.fundraisers[f]
+	fundraiser.AmountRaisedInCents += amountInCents
+	if amountInCents > 0 {
+		fundraiser.DonorsCount++
+	}
+	if fundraiser.GoalInCents > 0 {
+		fundraiser.PercentFunded = float64(fundraiser.AmountRaisedInCents) / float64(fundraiser.GoalInCents) * 100
+	}
+}
+
+// decodeInto overlays a JSON or form request body onto target, keeping any
+// fields the request leaves out. Form values are returned so callers can
+// resolve reference fields such as account_id.
+func decodeInto(r *http.Request, target any) (url.Values, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		err := json.NewDecoder(r.Body).Decode(target)
+		return url.Values{}, err == nil || err == io.EOF
+	}
+
+	values, err := params(r)
+	if err != nil {
+		return nil, false
+	}
+
+	for key := range values {
+		raw := values.Get(key)
+
+		candidates := []any{raw}
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			candidates = append([]any{n}, candidates...)
+		} else if b, err := strconv.ParseBool(raw); err == nil {
+			candidates = append([]any{b}, candidates...)
+		}
+
+		for _, candidate := range candidates {
+			field, err := json.Marshal(map[string]any{key: candidate})
+			if err != nil {
+				return nil, false
+			}
+
+			if json.Unmarshal(field, target) == nil {
+				break
+			}
+		}
+	}
+
+	return values, true
+}
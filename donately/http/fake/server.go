@@ -0,0 +1,197 @@
+// Package fake provides an in-memory stand-in for the Donately API. It serves
+// the endpoints donatelyhttp.Client uses with the same APIResponse envelope and
+// error shapes, so commands can be exercised without a sandbox account:
+//
+//	srv := httptest.NewServer(fake.New(seed))
+//	client, _ := donatelyhttp.NewDonatelyClient(donatelyhttp.WithBaseURL(srv.URL), donatelyhttp.WithAPIKey("test"))
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+	donatelyhttp "github.com/willmadison/donately-sync-tools/donately/http"
+)
+
+const defaultLimit = 20
+
+// Seed is the initial state of a fake server.
+type Seed struct {
+	APIKey        string                  `json:"api_key,omitempty"`
+	Me            *donately.Person        `json:"me,omitempty"`
+	Accounts      []donately.Account      `json:"accounts"`
+	People        []donately.Person       `json:"people"`
+	Donations     []donately.Donation     `json:"donations"`
+	Subscriptions []donately.Subscription `json:"subscriptions"`
+	Campaigns     []donately.Campaign     `json:"campaigns"`
+	Fundraisers   []donately.Fundraiser   `json:"fundraisers"`
+}
+
+func LoadSeed(r io.Reader) (Seed, error) {
+	var seed Seed
+	if err := json.NewDecoder(r).Decode(&seed); err != nil {
+		return Seed{}, fmt.Errorf("failed to decode seed: %w", err)
+	}
+
+	return seed, nil
+}
+
+// Server is an http.Handler backed by in-memory state. It is safe for
+// concurrent use.
+type Server struct {
+	mu sync.Mutex
+
+	apiKey        string
+	me            donately.Person
+	accounts      []donately.Account
+	people        []donately.Person
+	donations     []donately.Donation
+	subscriptions []donately.Subscription
+	campaigns     []donately.Campaign
+	fundraisers   []donately.Fundraiser
+
+	nextID        int
+	nextRequestID atomic.Int64
+
+	mux *http.ServeMux
+}
+
+func New(seed Seed) *Server {
+	s := &Server{
+		apiKey:        seed.APIKey,
+		accounts:      append([]donately.Account(nil), seed.Accounts...),
+		people:        append([]donately.Person(nil), seed.People...),
+		donations:     append([]donately.Donation(nil), seed.Donations...),
+		subscriptions: append([]donately.Subscription(nil), seed.Subscriptions...),
+		campaigns:     append([]donately.Campaign(nil), seed.Campaigns...),
+		fundraisers:   append([]donately.Fundraiser(nil), seed.Fundraisers...),
+		mux:           http.NewServeMux(),
+	}
+
+	if seed.Me != nil {
+		s.me = *seed.Me
+	} else {
+		s.me = donately.Person{ID: "person_me", Email: "me@example.com", FirstName: "Fake", LastName: "Admin", Accounts: s.accounts}
+	}
+
+	s.routes()
+
+	return s
+}
+
+// Snapshot returns the current state in seed form.
+func (s *Server) Snapshot() Seed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	me := s.me
+
+	return Seed{
+		APIKey:        s.apiKey,
+		Me:            &me,
+		Accounts:      append([]donately.Account(nil), s.accounts...),
+		People:        append([]donately.Person(nil), s.people...),
+		Donations:     append([]donately.Donation(nil), s.donations...),
+		Subscriptions: append([]donately.Subscription(nil), s.subscriptions...),
+		Campaigns:     append([]donately.Campaign(nil), s.campaigns...),
+		Fundraisers:   append([]donately.Fundraiser(nil), s.fundraisers...),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/v2")
+
+	auth := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok || token == "" || (s.apiKey != "" && token != s.apiKey) {
+		s.writeError(w, http.StatusUnauthorized, "authentication_error", "unauthorized", "invalid or missing API key")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /accounts", s.listAccounts)
+	s.mux.HandleFunc("GET /accounts/{id}", s.findAccount)
+
+	s.mux.HandleFunc("GET /me", s.findMe)
+	s.mux.HandleFunc("GET /me/donations", s.listMyDonations)
+	s.mux.HandleFunc("GET /me/subscriptions", s.listMySubscriptions)
+
+	s.mux.HandleFunc("GET /people", s.listPeople)
+	s.mux.HandleFunc("GET /people/{id}", s.findPerson)
+	s.mux.HandleFunc("POST /people", s.savePerson)
+	s.mux.HandleFunc("POST /people/{id}", s.savePerson)
+
+	s.mux.HandleFunc("GET /donations", s.listDonations)
+	s.mux.HandleFunc("GET /donations/{id}", s.findDonation)
+	s.mux.HandleFunc("POST /donations", s.saveDonation)
+	s.mux.HandleFunc("POST /donations/{id}", s.saveDonation)
+	s.mux.HandleFunc("POST /donations/{id}/refund", s.refundDonation)
+	s.mux.HandleFunc("POST /donations/{id}/receipt", s.sendReceipt)
+
+	s.mux.HandleFunc("GET /subscriptions", s.listSubscriptions)
+	s.mux.HandleFunc("GET /subscriptions/{id}", s.findSubscription)
+	s.mux.HandleFunc("POST /subscriptions", s.saveSubscription)
+	s.mux.HandleFunc("POST /subscriptions/{id}", s.saveSubscription)
+
+	s.mux.HandleFunc("GET /campaigns", s.listCampaigns)
+	s.mux.HandleFunc("GET /campaigns/{id}", s.findCampaign)
+	s.mux.HandleFunc("POST /campaigns", s.saveCampaign)
+	s.mux.HandleFunc("POST /campaigns/{id}", s.saveCampaign)
+	s.mux.HandleFunc("DELETE /campaigns/{id}", s.deleteCampaign)
+
+	s.mux.HandleFunc("GET /fundraisers", s.listFundraisers)
+	s.mux.HandleFunc("GET /fundraisers/{id}", s.findFundraiser)
+	s.mux.HandleFunc("POST /fundraisers", s.saveFundraiser)
+	s.mux.HandleFunc("POST /fundraisers/{id}", s.saveFundraiser)
+
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, http.StatusNotFound, "invalid_request_error", "not_found", fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+	})
+}
+
+// newID must be called with s.mu held.
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s_fake%d", prefix, s.nextID)
+}
+
+func (s *Server) requestID() string {
+	return fmt.Sprintf("req_fake%d", s.nextRequestID.Add(1))
+}
+
+func (s *Server) writeData(w http.ResponseWriter, status int, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "api_error", "internal_error", err.Error())
+		return
+	}
+
+	writeJSON(w, status, donatelyhttp.APIResponse{Data: raw, RequestID: s.requestID()})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, errType, code, message string) {
+	writeJSON(w, status, donatelyhttp.APIResponse{Type: errType, Code: code, Message: message, RequestID: s.requestID()})
+}
+
+func (s *Server) notFound(w http.ResponseWriter, kind, id string) {
+	s.writeError(w, http.StatusNotFound, "invalid_request_error", "not_found", fmt.Sprintf("%s %s not found", kind, id))
+}
+
+func (s *Server) invalid(w http.ResponseWriter, message string) {
+	s.writeError(w, http.StatusUnprocessableEntity, "invalid_request_error", "invalid_params", message)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,399 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+	donatelyhttp "github.com/willmadison/donately-sync-tools/donately/http"
+	"github.com/willmadison/donately-sync-tools/donately/http/fake"
+)
+
+const testAPIKey = "test-key"
+
+var (
+	testAccount  = donately.Account{ID: "act_test", Title: "Test Chapter", Subdomain: "test", Currency: "usd"}
+	testCampaign = donately.Campaign{ID: "cmp_test", Title: "Capital Campaign", Account: testAccount, GoalInCents: 100000}
+)
+
+// testSeed has more people than fit on one page, so scans have to paginate.
+func testSeed(people int) fake.Seed {
+	seed := fake.Seed{
+		APIKey:    testAPIKey,
+		Accounts:  []donately.Account{testAccount},
+		Campaigns: []donately.Campaign{testCampaign},
+	}
+
+	for i := range people {
+		seed.People = append(seed.People, donately.Person{
+			ID:        fmt.Sprintf("person_%03d", i),
+			Email:     fmt.Sprintf("donor%03d@example.com", i),
+			FirstName: "Donor",
+			LastName:  fmt.Sprintf("%03d", i),
+			Accounts:  []donately.Account{testAccount},
+		})
+	}
+
+	return seed
+}
+
+func newTestClient(t *testing.T, seed fake.Seed, opts ...donatelyhttp.Option) donatelyhttp.Client {
+	t.Helper()
+
+	return newTestClientFor(t, fake.New(seed), opts...)
+}
+
+func newTestClientFor(t *testing.T, handler http.Handler, opts ...donatelyhttp.Option) donatelyhttp.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]donatelyhttp.Option{
+		donatelyhttp.WithBaseURL(srv.URL),
+		donatelyhttp.WithAPIKey(testAPIKey),
+		donatelyhttp.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		donatelyhttp.WithRateLimit(donatelyhttp.RateLimit{}),
+		donatelyhttp.WithRetryPolicy(donatelyhttp.RetryPolicy{MaxAttempts: 1}),
+	}, opts...)
+
+	client, err := donatelyhttp.NewDonatelyClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestResolveAccount(t *testing.T) {
+	client := newTestClient(t, testSeed(0))
+
+	for _, ref := range []string{"act_test", "test", "Test Chapter"} {
+		account, err := donatelyhttp.ResolveAccount(context.Background(), client, ref)
+		if err != nil {
+			t.Errorf("ResolveAccount(%q): %v", ref, err)
+			continue
+		}
+
+		if account.ID != testAccount.ID {
+			t.Errorf("ResolveAccount(%q) = %s, want %s", ref, account.ID, testAccount.ID)
+		}
+	}
+}
+
+func TestPeoplePaginatesThroughTheAccount(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		client := newTestClient(t, testSeed(250), donatelyhttp.WithConcurrency(concurrency))
+
+		people, err := donatelyhttp.Collect(client.People(context.Background(), testAccount, donatelyhttp.PersonFilter{}))
+		if err != nil {
+			t.Fatalf("concurrency %d: %v", concurrency, err)
+		}
+
+		if len(people) != 250 {
+			t.Fatalf("concurrency %d: got %d people, want 250", concurrency, len(people))
+		}
+
+		for i, person := range people {
+			if want := fmt.Sprintf("person_%03d", i); person.ID != want {
+				t.Fatalf("concurrency %d: people[%d] = %s, want %s", concurrency, i, person.ID, want)
+			}
+		}
+	}
+}
+
+func TestPeopleByEmail(t *testing.T) {
+	tests := []struct {
+		name   string
+		emails []string
+	}{
+		{name: "looked up one by one", emails: []string{"DONOR007@example.com", "donor142@example.com", "nobody@example.com"}},
+		{name: "matched during a scan", emails: []string{
+			"donor000@example.com", "donor001@example.com", "donor002@example.com", "donor003@example.com",
+			"donor004@example.com", "donor005@example.com", "donor006@example.com", "DONOR007@example.com",
+			"donor008@example.com", "donor009@example.com", "donor142@example.com", "nobody@example.com",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, testSeed(150), donatelyhttp.WithConcurrency(2))
+
+			found, err := donatelyhttp.PeopleByEmail(context.Background(), client, testAccount, tt.emails)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(found) != len(tt.emails)-1 {
+				t.Errorf("found %d people, want %d", len(found), len(tt.emails)-1)
+			}
+
+			if person := found["donor007@example.com"]; person.ID != "person_007" {
+				t.Errorf("donor007 = %q, want person_007", person.ID)
+			}
+
+			if person := found["donor142@example.com"]; person.ID != "person_142" {
+				t.Errorf("donor142 = %q, want person_142", person.ID)
+			}
+
+			if _, ok := found["nobody@example.com"]; ok {
+				t.Error("found a person for an unknown address")
+			}
+		})
+	}
+}
+
+func TestSavePerson(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, testSeed(1))
+
+	saved, err := client.SavePerson(ctx, donately.Person{
+		Email:     "ada@example.com",
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Accounts:  []donately.Account{testAccount},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved.ID == "" {
+		t.Fatal("saved person has no ID")
+	}
+
+	found, err := client.FindPerson(ctx, saved.ID, testAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Email != "ada@example.com" || found.FirstName != "Ada" {
+		t.Errorf("found %+v, want Ada's record", found)
+	}
+
+	_, err = client.SavePerson(ctx, donately.Person{
+		Email:    "ADA@example.com",
+		Accounts: []donately.Account{testAccount},
+	})
+	if !errors.Is(err, donatelyhttp.ErrValidation) {
+		t.Errorf("duplicate email error = %v, want ErrValidation", err)
+	}
+}
+
+func TestSaveAndRefundDonation(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, testSeed(1))
+
+	saved, err := client.SaveDonation(ctx, donately.Donation{
+		AmountInCents: 2500,
+		DonationType:  donately.DonationTypeCash,
+		Person:        donately.Person{Email: "donor000@example.com"},
+		Account:       testAccount,
+		Campaign:      testCampaign,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved.Person.ID != "person_000" {
+		t.Errorf("donation person = %q, want person_000", saved.Person.ID)
+	}
+
+	if got := saved.NetAmount(); got != donately.NewMoney(2500, "usd") {
+		t.Errorf("net amount = %v, want $25.00", got)
+	}
+
+	donations, err := donatelyhttp.Collect(client.Donations(ctx, testAccount, donatelyhttp.DonationFilter{CampaignID: testCampaign.ID}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(donations) != 1 || donations[0].ID != saved.ID {
+		t.Fatalf("campaign donations = %+v, want just %s", donations, saved.ID)
+	}
+
+	campaign, err := client.FindCampaign(ctx, testCampaign.ID, testAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if campaign.AmountRaisedInCents != 2500 {
+		t.Errorf("amount raised = %d, want 2500", campaign.AmountRaisedInCents)
+	}
+
+	if err := client.RefundDonation(ctx, saved, "duplicate"); err != nil {
+		t.Fatal(err)
+	}
+
+	refunded, err := client.FindDonation(ctx, saved.ID, testAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if refunded.Status != donately.DonationRefunded || len(refunded.Refunds) != 1 {
+		t.Errorf("refunded donation = %+v, want one refund", refunded)
+	}
+
+	if got := refunded.NetAmount(); !got.IsZero() {
+		t.Errorf("net amount after refund = %v, want zero", got)
+	}
+
+	if err := client.RefundDonation(ctx, saved, "again"); !errors.Is(err, donatelyhttp.ErrValidation) {
+		t.Errorf("second refund error = %v, want ErrValidation", err)
+	}
+}
+
+func TestSaveDonationRejectsUnknownTypes(t *testing.T) {
+	client := newTestClient(t, testSeed(1))
+
+	_, err := client.SaveDonation(context.Background(), donately.Donation{
+		AmountInCents: 100,
+		DonationType:  "barter",
+		Account:       testAccount,
+	})
+	if !errors.Is(err, donatelyhttp.ErrValidation) {
+		t.Errorf("error = %v, want ErrValidation", err)
+	}
+}
+
+func TestErrorsClassify(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, testSeed(0))
+
+	if _, err := client.FindCampaign(ctx, "cmp_missing", testAccount); !errors.Is(err, donatelyhttp.ErrNotFound) {
+		t.Errorf("missing campaign error = %v, want ErrNotFound", err)
+	}
+
+	unauthorized := newTestClient(t, testSeed(0), donatelyhttp.WithAPIKey("wrong-key"))
+
+	if _, err := unauthorized.FindAccount(ctx, testAccount.ID); !errors.Is(err, donatelyhttp.ErrUnauthorized) {
+		t.Errorf("wrong key error = %v, want ErrUnauthorized", err)
+	}
+}
+
+// retryLaterOnce lets the first POST to a path ending in suffix through to
+// next, then answers it "retry later" anyway, the way Donately sometimes does
+// after a write has gone through.
+type retryLaterOnce struct {
+	next     http.Handler
+	suffix   string
+	attempts atomic.Int32
+}
+
+func (h *retryLaterOnce) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, h.suffix) {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	if h.attempts.Add(1) > 1 {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	h.next.ServeHTTP(httptest.NewRecorder(), r)
+	io.WriteString(w, "Retry later")
+}
+
+func retryingOptions() []donatelyhttp.Option {
+	return []donatelyhttp.Option{
+		donatelyhttp.WithRetryPolicy(donatelyhttp.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}),
+	}
+}
+
+func TestSaveDonationAfterRetryLaterDoesNotDuplicate(t *testing.T) {
+	ctx := context.Background()
+
+	server := fake.New(testSeed(1))
+	flaky := &retryLaterOnce{next: server, suffix: "/donations"}
+	client := newTestClientFor(t, flaky, retryingOptions()...)
+
+	donation := donately.Donation{
+		AmountInCents: 2500,
+		DonationType:  donately.DonationTypeCash,
+		Person:        donately.Person{ID: "person_000", Email: "donor000@example.com"},
+		Account:       testAccount,
+		Campaign:      testCampaign,
+	}
+
+	key := donately.DonationIdempotencyKey(donation, "row 1")
+
+	saved, err := client.SaveDonation(ctx, donation.WithIdempotencyKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := flaky.attempts.Load(); got != 1 {
+		t.Errorf("donation posted %d times, want 1", got)
+	}
+
+	donations := server.Snapshot().Donations
+	if len(donations) != 1 {
+		t.Fatalf("created %d donations, want 1", len(donations))
+	}
+
+	if donations[0].IdempotencyKey() != key {
+		t.Errorf("tracking codes = %q, want them to carry %s", donations[0].TrackingCodes, key)
+	}
+
+	if saved.ID != donations[0].ID {
+		t.Errorf("saved donation = %s, want the one the first attempt created, %s", saved.ID, donations[0].ID)
+	}
+}
+
+func TestWritesWithoutAKeyAreNotRetriedAfterRetryLater(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		suffix string
+		save   func(donatelyhttp.Client) error
+	}{
+		{
+			name:   "donation without an idempotency key",
+			suffix: "/donations",
+			save: func(client donatelyhttp.Client) error {
+				_, err := client.SaveDonation(ctx, donately.Donation{
+					AmountInCents: 2500,
+					DonationType:  donately.DonationTypeCash,
+					Person:        donately.Person{ID: "person_000", Email: "donor000@example.com"},
+					Account:       testAccount,
+					Campaign:      testCampaign,
+				})
+				return err
+			},
+		},
+		{
+			name:   "person",
+			suffix: "/people",
+			save: func(client donatelyhttp.Client) error {
+				_, err := client.SavePerson(ctx, donately.Person{Email: "ada@example.com", Accounts: []donately.Account{testAccount}})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fake.New(testSeed(1))
+			flaky := &retryLaterOnce{next: server, suffix: tt.suffix}
+
+			if err := tt.save(newTestClientFor(t, flaky, retryingOptions()...)); !errors.Is(err, donatelyhttp.ErrRateLimited) {
+				t.Errorf("error = %v, want ErrRateLimited", err)
+			}
+
+			if got := flaky.attempts.Load(); got != 1 {
+				t.Errorf("posted %d times, want 1", got)
+			}
+		})
+	}
+}
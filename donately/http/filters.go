@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+// DonationFilter narrows ListDonations server side. Zero values are ignored.
+type DonationFilter struct {
+	CampaignID    string
+	PersonID      string
+	Status        donately.DonationStatus
+	DonationType  donately.DonationType
+	Livemode      *bool
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+func (f DonationFilter) apply(params url.Values) {
+	setString(params, "campaign_id", f.CampaignID)
+	setString(params, "person_id", f.PersonID)
+	setString(params, "status", string(f.Status))
+	setString(params, "donation_type", string(f.DonationType))
+
+	if f.Livemode != nil {
+		params.Set("livemode", strconv.FormatBool(*f.Livemode))
+	}
+
+	setUnix(params, "created_after", f.CreatedAfter)
+	setUnix(params, "created_before", f.CreatedBefore)
+	setUnix(params, "updated_after", f.UpdatedAfter)
+	setUnix(params, "updated_before", f.UpdatedBefore)
+}
+
+// PersonFilter narrows ListPeople server side. Email is an exact match while
+// Search matches names and emails loosely.
+type PersonFilter struct {
+	Email  string
+	Search string
+}
+
+func (f PersonFilter) apply(params url.Values) {
+	setString(params, "email", f.Email)
+	setString(params, "search", f.Search)
+}
+
+// FundraiserFilter narrows ListFundraisers server side.
+type FundraiserFilter struct {
+	CampaignID string
+	PersonID   string
+	Status     string
+}
+
+func (f FundraiserFilter) apply(params url.Values) {
+	setString(params, "campaign_id", f.CampaignID)
+	setString(params, "person_id", f.PersonID)
+	setString(params, "status", f.Status)
+}
+
+// SubscriptionFilter narrows ListSubscriptions server side.
+type SubscriptionFilter struct {
+	Status             string
+	CampaignID         string
+	PersonID           string
+	RecurringFrequency donately.RecurringFrequency
+}
+
+func (f SubscriptionFilter) apply(params url.Values) {
+	setString(params, "status", f.Status)
+	setString(params, "campaign_id", f.CampaignID)
+	setString(params, "person_id", f.PersonID)
+	setString(params, "recurring_frequency", string(f.RecurringFrequency))
+}
+
+// CampaignFilter narrows ListCampaigns server side.
+type CampaignFilter struct {
+	Status string
+}
+
+func (f CampaignFilter) apply(params url.Values) {
+	setString(params, "status", f.Status)
+}
+
+func setString(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
+
+// setUnix sends timestamps as unix seconds, matching how Donately reports them.
+func setUnix(params url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		params.Set(key, strconv.FormatInt(value.Unix(), 10))
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Writable model fields carry a `form` struct tag naming the parameter
+// Donately accepts for them; untagged fields are read-only and never sent.
+//
+//	Title    string  `form:"title"`
+//	Account  Account `form:"account_id,always"`
+//	Person   Person  `form:"email,ref=Email"`
+//
+// Struct-typed fields are sent as a reference to the nested record: its ID, or
+// the field named by ref. always marks identifying fields that are sent even
+// in patch mode when they haven't changed.
+
+type formField struct {
+	name   string
+	ref    string
+	always bool
+	index  []int
+}
+
+func formFields(t reflect.Type) []formField {
+	var fields []formField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag, ok := sf.Tag.Lookup("form")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := formField{name: parts[0], ref: "ID", index: sf.Index}
+
+		for _, option := range parts[1:] {
+			switch {
+			case option == "always":
+				field.always = true
+			case strings.HasPrefix(option, "ref="):
+				field.ref = strings.TrimPrefix(option, "ref=")
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return reflect.Value{}, errors.New("cannot form-encode a nil pointer")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("cannot form-encode %s", rv.Type())
+	}
+
+	return rv, nil
+}
+
+// formValue renders v as a form parameter. set reports whether the value was
+// provided at all: non-zero, or a non-nil pointer (which is how callers say
+// "send this even though it's zero").
+func formValue(v reflect.Value, ref string) (value string, set bool, err error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false, nil
+		}
+
+		if v.Elem().Kind() == reflect.Struct {
+			return formValue(v.Elem(), ref)
+		}
+
+		value, _, err := formValue(v.Elem(), ref)
+		return value, true, err
+	}
+
+	if v.CanInterface() {
+		if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return "", false, err
+			}
+			return string(text), !v.IsZero(), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), v.String() != "", nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), v.Int() != 0, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), v.Uint() != 0, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), v.Float() != 0, nil
+	case reflect.Struct:
+		nested := v.FieldByName(ref)
+		if !nested.IsValid() {
+			return "", false, fmt.Errorf("%s has no field %s to reference", v.Type(), ref)
+		}
+		return formValue(nested, "ID")
+	}
+
+	return "", false, fmt.Errorf("unsupported form field type %s", v.Type())
+}
+
+// EncodeForm renders the writable, non-zero fields of v as form values.
+func EncodeForm(v any) (url.Values, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+
+	for _, field := range formFields(rv.Type()) {
+		value, set, err := formValue(rv.FieldByIndex(field.index), field.ref)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+
+		if set {
+			values.Set(field.name, value)
+		}
+	}
+
+	return values, nil
+}
+
+// EncodePatch renders only the writable fields that differ between original
+// and updated, plus any `always` fields. A field cleared in updated is sent with
+// its zero value (empty for nil pointers) so Donately clears it too.
+func EncodePatch(original, updated any) (url.Values, error) {
+	before, err := structValue(original)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := structValue(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	if before.Type() != after.Type() {
+		return nil, fmt.Errorf("cannot patch %s with %s", before.Type(), after.Type())
+	}
+
+	values := url.Values{}
+
+	for _, field := range formFields(after.Type()) {
+		oldValue, oldSet, err := formValue(before.FieldByIndex(field.index), field.ref)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+
+		newValue, newSet, err := formValue(after.FieldByIndex(field.index), field.ref)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+
+		unchanged := oldSet == newSet && oldValue == newValue
+
+		if unchanged && !(field.always && newSet) {
+			continue
+		}
+
+		values.Set(field.name, newValue)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+type formRecord struct {
+	Name      string                `form:"name"`
+	Count     int64                 `form:"count"`
+	Ratio     float64               `form:"ratio"`
+	Active    bool                  `form:"active"`
+	Limit     *int64                `form:"limit"`
+	Public    *bool                 `form:"public"`
+	Note      *string               `form:"note"`
+	Type      donately.DonationType `form:"type"`
+	Starts    *donately.Date        `form:"starts"`
+	Account   donately.Account      `form:"account_id,always"`
+	Person    donately.Person       `form:"email,ref=Email"`
+	Campaign  *donately.Campaign    `form:"campaign_id"`
+	Skipped   string                `form:"-"`
+	ReadOnly  string
+	internals string
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestEncodeForm(t *testing.T) {
+	tests := []struct {
+		name   string
+		record formRecord
+		want   url.Values
+	}{
+		{
+			name:   "zero values are left out",
+			record: formRecord{Skipped: "x", ReadOnly: "y", internals: "z"},
+			want:   url.Values{},
+		},
+		{
+			name: "scalars",
+			record: formRecord{
+				Name:   "Spring Drive",
+				Count:  3,
+				Ratio:  0.25,
+				Active: true,
+				Type:   donately.DonationTypeCash,
+			},
+			want: url.Values{
+				"name":   {"Spring Drive"},
+				"count":  {"3"},
+				"ratio":  {"0.25"},
+				"active": {"true"},
+				"type":   {"cash"},
+			},
+		},
+		{
+			name: "pointers send their zero values",
+			record: formRecord{
+				Limit:  ptr(int64(0)),
+				Public: ptr(false),
+				Note:   ptr(""),
+			},
+			want: url.Values{
+				"limit":  {"0"},
+				"public": {"false"},
+				"note":   {""},
+			},
+		},
+		{
+			name: "nested records are sent by reference",
+			record: formRecord{
+				Account:  donately.Account{ID: "act_1", Title: "Chapter"},
+				Person:   donately.Person{ID: "person_1", Email: "ada@example.com"},
+				Campaign: &donately.Campaign{ID: "cmp_1"},
+				Starts:   &donately.Date{Year: 2024, Month: 3, Day: 9},
+			},
+			want: url.Values{
+				"account_id":  {"act_1"},
+				"email":       {"ada@example.com"},
+				"campaign_id": {"cmp_1"},
+				"starts":      {"2024-03-09"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeForm(tt.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeForm = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFormRejectsNonStructs(t *testing.T) {
+	var missing *formRecord
+
+	for _, v := range []any{missing, "text", 42} {
+		if _, err := EncodeForm(v); err == nil {
+			t.Errorf("EncodeForm(%#v) succeeded, want an error", v)
+		}
+	}
+
+	type unsupported struct {
+		Tags []string `form:"tags"`
+	}
+
+	if _, err := EncodeForm(unsupported{Tags: []string{"a"}}); err == nil {
+		t.Error("EncodeForm encoded a slice, want an error")
+	}
+}
+
+func TestEncodePatch(t *testing.T) {
+	original := formRecord{
+		Name:     "Spring Drive",
+		Count:    3,
+		Note:     ptr("first"),
+		Account:  donately.Account{ID: "act_1"},
+		Campaign: &donately.Campaign{ID: "cmp_1"},
+	}
+
+	tests := []struct {
+		name   string
+		update func(*formRecord)
+		want   url.Values
+	}{
+		{
+			name:   "only always fields when nothing changed",
+			update: func(*formRecord) {},
+			want:   url.Values{"account_id": {"act_1"}},
+		},
+		{
+			name: "changed fields",
+			update: func(r *formRecord) {
+				r.Name = "Fall Drive"
+				r.Active = true
+			},
+			want: url.Values{
+				"account_id": {"act_1"},
+				"name":       {"Fall Drive"},
+				"active":     {"true"},
+			},
+		},
+		{
+			name: "cleared fields are sent empty",
+			update: func(r *formRecord) {
+				r.Count = 0
+				r.Note = nil
+				r.Campaign = nil
+			},
+			want: url.Values{
+				"account_id":  {"act_1"},
+				"count":       {"0"},
+				"note":        {""},
+				"campaign_id": {""},
+			},
+		},
+		{
+			name: "changed references",
+			update: func(r *formRecord) {
+				r.Campaign = &donately.Campaign{ID: "cmp_2", Title: "ignored"}
+			},
+			want: url.Values{
+				"account_id":  {"act_1"},
+				"campaign_id": {"cmp_2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := original
+			tt.update(&updated)
+
+			got, err := EncodePatch(original, updated)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodePatch = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodePatchRejectsMismatchedTypes(t *testing.T) {
+	if _, err := EncodePatch(donately.Campaign{}, donately.Donation{}); err == nil {
+		t.Error("EncodePatch accepted a campaign and a donation, want an error")
+	}
+}
@@ -1,24 +1,29 @@
 package http
 
 import (
-	"context"
+	"log/slog"
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/willmadison/donately-sync-tools/donately"
 )
 
-func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentStore, account donately.Account, campaign donately.Campaign, collectionRecords []donately.CollectionReportRecord) func(*gin.Context) {
+// CampaignOverviewHandler reports every pledged donor's progress. All amounts
+// are in the account's reporting currency, converted through rates as needed.
+func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentStore, account donately.Account, campaign donately.Campaign, collectionRecords []donately.CollectionReportRecord, rates donately.ExchangeRates) func(*gin.Context) {
+	currency := account.ReportingCurrency()
+
 	return func(c *gin.Context) {
-		var everyone []donately.Person
+		ctx := c.Request.Context()
 
-		offset := 0
-		limit := 100
+		pledgeAmountByEmail := map[string]donately.Money{}
+		var pledgedEmails []string
 
-		for {
-			donors, err := client.ListPeople(account, offset, limit)
+		for _, collectionRecord := range collectionRecords {
+			pledged, err := rates.Convert(collectionRecord.AmountPledged, currency)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error":   "internal server error",
@@ -27,57 +32,72 @@ func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentS
 				return
 			}
 
-			if len(donors) == 0 {
-				break
+			email := strings.ToLower(collectionRecord.EmailAddress)
+			pledgeAmountByEmail[email] = pledged
+
+			if !pledged.IsZero() {
+				pledgedEmails = append(pledgedEmails, email)
 			}
+		}
 
-			everyone = append(everyone, donors...)
-			offset += len(donors) + 1
+		peopleByEmail, err := PeopleByEmail(ctx, client, account, pledgedEmails)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal server error",
+				"details": err.Error(),
+			})
+			return
 		}
 
-		peopleById := map[string]donately.Person{}
+		var everyone []donately.Person
 
-		for _, person := range everyone {
-			peopleById[person.ID] = person
+		for _, email := range pledgedEmails {
+			if person, present := peopleByEmail[email]; present {
+				everyone = append(everyone, person)
+				delete(peopleByEmail, email)
+			}
 		}
 
-		pledgeAmountByEmail := map[string]float64{}
-
-		for _, collectionRecord := range collectionRecords {
-			pledgeAmountByEmail[strings.ToLower(collectionRecord.EmailAddress)] = collectionRecord.AmountPledged
+		allDonations, err := Collect(client.Donations(ctx, account, DonationFilter{CampaignID: campaign.ID}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal server error",
+				"details": err.Error(),
+			})
+			return
 		}
 
-		var allDonations []donately.Donation
+		fundraisers, err := Collect(client.Fundraisers(ctx, account, FundraiserFilter{CampaignID: campaign.ID}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal server error",
+				"details": err.Error(),
+			})
+			return
+		}
 
-		offset = 0
-		limit = 100
+		if fundraisers == nil {
+			fundraisers = []donately.Fundraiser{}
+		}
 
-		for {
-			donations, err := client.ListDonations(account, offset, limit)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "internal server error",
-					"details": err.Error(),
-				})
-				return
-			}
+		donately.ResolveFundraisers(allDonations, fundraisers)
 
-			if len(donations) == 0 {
-				break
-			}
+		fundraiserByPersonId := map[string]donately.Fundraiser{}
 
-			allDonations = append(allDonations, donations...)
-			offset += len(donations) + 1
+		for _, fundraiser := range fundraisers {
+			fundraiserByPersonId[fundraiser.Person.ID] = fundraiser
 		}
 
 		donationsByPersonId := map[string][]donately.Donation{}
 
 		for _, donation := range allDonations {
-			if _, present := donationsByPersonId[donation.Person.ID]; !present {
-				donationsByPersonId[donation.Person.ID] = []donately.Donation{}
+			personID := donation.AttributedPersonID()
+
+			if _, present := donationsByPersonId[personID]; !present {
+				donationsByPersonId[personID] = []donately.Donation{}
 			}
 
-			donationsByPersonId[donation.Person.ID] = append(donationsByPersonId[donation.Person.ID], donation)
+			donationsByPersonId[personID] = append(donationsByPersonId[personID], donation)
 		}
 
 		var donors []donately.Donor
@@ -93,7 +113,7 @@ func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentS
 				donations = []donately.Donation{}
 			}
 
-			adjustments, err := adjustmentStore.GetAdustmentsByPerson(context.Background(), person)
+			adjustments, err := adjustmentStore.GetAdustmentsByPerson(ctx, person)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error":   "internal server error",
@@ -106,18 +126,45 @@ func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentS
 				adjustments = []donately.Adjustment{}
 			}
 
+			for i, adjustment := range adjustments {
+				adjustments[i].Amount, err = rates.Convert(adjustment.Amount, currency)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error":   "internal server error",
+						"details": err.Error(),
+					})
+					return
+				}
+			}
+
 			pledge := pledgeAmountByEmail[strings.ToLower(person.Email)]
 
-			if pledge == 0 {
+			if pledge.IsZero() {
 				continue
 			}
 
-			donors = append(donors, donately.Donor{
+			donated, err := donately.NetTotal(donations, currency, rates)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "internal server error",
+					"details": err.Error(),
+				})
+				return
+			}
+
+			donor := donately.Donor{
 				Person:      person,
 				Adjustments: adjustments,
 				Donations:   donations,
 				Pledge:      pledge,
-			})
+				Donated:     donated,
+			}
+
+			if fundraiser, present := fundraiserByPersonId[person.ID]; present {
+				donor.Fundraiser = &fundraiser
+			}
+
+			donors = append(donors, donor)
 		}
 
 		sort.Slice(donors, func(i, j int) bool {
@@ -142,8 +189,26 @@ func CampaignOverviewHandler(client Client, adjustmentStore donately.AdjustmentS
 			AmountRaisedInCents: campaign.AmountRaisedInCents,
 			PercentFunded:       campaign.PercentFunded,
 			Donors:              donors,
+			Fundraisers:         fundraisers,
 		}
 
 		c.JSON(http.StatusOK, overview)
 	}
 }
+
+// RequestLogger logs each request served by gin through logger.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+
+		c.Next()
+
+		logger.Info("http request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(startedAt)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveParams are query/form parameters that carry donor PII or secrets
+// and must never reach the logs.
+var sensitiveParams = map[string]bool{
+	"email":            true,
+	"first_name":       true,
+	"last_name":        true,
+	"phone_number":     true,
+	"street_address":   true,
+	"street_address_2": true,
+	"zip_code":         true,
+	"api_key":          true,
+	"token":            true,
+	"search":           true,
+}
+
+// RedactField returns value, or a mask in its place when a log field or
+// parameter named key is one that must never reach the logs.
+func RedactField(key, value string) string {
+	if sensitiveParams[strings.ToLower(key)] {
+		return redacted
+	}
+
+	return value
+}
+
+// redactURL renders u's path and query with sensitive parameter values masked.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return u.Path
+	}
+
+	for key := range query {
+		if sensitiveParams[strings.ToLower(key)] {
+			query.Set(key, redacted)
+		}
+	}
+
+	return u.Path + "?" + query.Encode()
+}
+
+// redactTransportError strips the query string (and thus any PII) from the URL
+// that net/http embeds in transport errors.
+func redactTransportError(err error, u *url.URL) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURL(u)
+	}
+
+	return err
+}
+
+// redactSecret masks everything but the last four characters of a credential.
+func redactSecret(secret string) string {
+	if len(secret) <= 4 {
+		return redacted
+	}
+
+	return redacted + "..." + secret[len(secret)-4:]
+}
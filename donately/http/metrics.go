@@ -0,0 +1,119 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	clientRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "client",
+		Name:      "requests_total",
+		Help:      "Donately API requests by method, endpoint and status (\"error\" when no response arrived).",
+	}, []string{"method", "endpoint", "status"})
+
+	clientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "donately",
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of Donately API requests, per attempt.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "endpoint"})
+
+	clientRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "client",
+		Name:      "retries_total",
+		Help:      "Donately API request attempts beyond the first.",
+	}, []string{"method", "endpoint"})
+
+	webhookEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "webhook",
+		Name:      "events_total",
+		Help:      "Donately webhook deliveries by event type and outcome (applied, ignored, rejected, malformed).",
+	}, []string{"type", "outcome"})
+
+	serverRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "donately",
+		Subsystem: "server",
+		Name:      "requests_total",
+		Help:      "HTTP requests served by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	serverRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "donately",
+		Subsystem: "server",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests served by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// resourceSegments are the fixed parts of Donately paths; everything else is
+// an ID and is collapsed so metrics keep a bounded set of endpoints.
+var resourceSegments = map[string]bool{
+	"accounts":      true,
+	"people":        true,
+	"donations":     true,
+	"subscriptions": true,
+	"campaigns":     true,
+	"fundraisers":   true,
+	"me":            true,
+	"refund":        true,
+	"receipt":       true,
+}
+
+// endpointLabel turns e.g. "/donations/dn_123/refund?x=y" into
+// "/donations/{id}/refund".
+func endpointLabel(endpoint string) string {
+	path, _, _ := strings.Cut(endpoint, "?")
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if !resourceSegments[segment] {
+			segments[i] = "{id}"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+func observeClientRequest(method, endpoint string, attempt, status int, duration time.Duration) {
+	label := endpointLabel(endpoint)
+
+	statusLabel := "error"
+	if status > 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+
+	clientRequests.WithLabelValues(method, label, statusLabel).Inc()
+	clientRequestDuration.WithLabelValues(method, label).Observe(duration.Seconds())
+
+	if attempt > 1 {
+		clientRetries.WithLabelValues(method, label).Inc()
+	}
+}
+
+// RequestMetrics records request counts and latencies for each gin route.
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		serverRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		serverRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(startedAt).Seconds())
+	}
+}
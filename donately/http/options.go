@@ -0,0 +1,100 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL    = "https://api.donately.com/v2"
+	DefaultAPIVersion = "2019-03-15"
+	DefaultUserAgent  = "donately-sync-tools"
+)
+
+// Option configures a client built by NewDonatelyClient.
+type Option func(*donatelyClient)
+
+func WithAPIKey(apiKey string) Option {
+	return func(c *donatelyClient) {
+		c.APIKey = apiKey
+	}
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *donatelyClient) {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithAPIVersion sets the Donately-Version header sent with every request.
+func WithAPIVersion(version string) Option {
+	return func(c *donatelyClient) {
+		c.apiVersion = version
+	}
+}
+
+// WithHTTPClient uses a copy of hc, so later options such as WithTimeout
+// never mutate a client owned by the caller.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *donatelyClient) {
+		copied := *hc
+		c.client = &copied
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *donatelyClient) {
+		c.client.Transport = transport
+	}
+}
+
+// WithTimeout bounds each individual HTTP attempt; retries get a fresh timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *donatelyClient) {
+		c.client.Timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *donatelyClient) {
+		c.userAgent = userAgent
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *donatelyClient) {
+		c.logger = logger
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *donatelyClient) {
+		c.retryPolicy = policy
+	}
+}
+
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *donatelyClient) {
+		c.rateLimit = limit
+	}
+}
+
+// WithConcurrency lets full scans fetch up to n pages at once. Every request
+// still waits on the shared rate limiter.
+func WithConcurrency(n int) Option {
+	return func(c *donatelyClient) {
+		c.concurrency = max(n, 1)
+	}
+}
+
+// WithReplayAPIKey supplies a placeholder API key when none is configured, since
+// replayed cassettes never reach Donately.
+func WithReplayAPIKey() Option {
+	return func(c *donatelyClient) {
+		if c.APIKey == "" {
+			c.APIKey = "replay"
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+const defaultPageSize = 100
+
+type pageFetcher[T any] func(ctx context.Context, offset, limit int) ([]T, error)
+
+// paginate walks an offset/limit endpoint page by page, yielding each record
+// in order. Iteration stops at the first empty page, the first error (which is
+// yielded), or when the consumer stops ranging.
+//
+// With concurrency above one, a short first page is taken to be everything.
+// Only after a full one are later pages fetched concurrency at a time, still
+// yielded in offset order; a short page then marks the end, at the cost of up
+// to concurrency-1 wasted requests past it.
+func paginate[T any](ctx context.Context, pageSize, concurrency int, fetch pageFetcher[T]) iter.Seq2[T, error] {
+	if concurrency > 1 {
+		return paginateConcurrently(ctx, pageSize, concurrency, fetch)
+	}
+
+	return func(yield func(T, error) bool) {
+		offset := 0
+
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			page, err := fetch(ctx, offset, pageSize)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			if len(page) == 0 {
+				return
+			}
+
+			for _, record := range page {
+				if !yield(record, nil) {
+					return
+				}
+			}
+
+			offset += len(page)
+		}
+	}
+}
+
+func paginateConcurrently[T any](ctx context.Context, pageSize, concurrency int, fetch pageFetcher[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		first, err := fetch(ctx, 0, pageSize)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for _, record := range first {
+			if !yield(record, nil) {
+				return
+			}
+		}
+
+		if len(first) < pageSize {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		for offset := pageSize; ; offset += concurrency * pageSize {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			pages := make([][]T, concurrency)
+			errs := make([]error, concurrency)
+
+			var wg sync.WaitGroup
+			for i := range concurrency {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					pages[i], errs[i] = fetch(ctx, offset+i*pageSize, pageSize)
+				}()
+			}
+			wg.Wait()
+
+			for i, page := range pages {
+				if errs[i] != nil {
+					yield(zero, errs[i])
+					return
+				}
+
+				for _, record := range page {
+					if !yield(record, nil) {
+						return
+					}
+				}
+
+				if len(page) < pageSize {
+					return
+				}
+			}
+		}
+	}
+}
+
+// concurrencyOf reports how many pages client fetches at once.
+func concurrencyOf(client Client) int {
+	if c, ok := client.(interface{ maxConcurrency() int }); ok {
+		return c.maxConcurrency()
+	}
+
+	return 1
+}
+
+func (c *donatelyClient) maxConcurrency() int {
+	return c.concurrency
+}
+
+// Collect drains a paginated sequence into a slice, returning the first error encountered.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var all []T
+
+	for record, err := range seq {
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, record)
+	}
+
+	return all, nil
+}
+
+func (c *donatelyClient) Accounts(ctx context.Context) iter.Seq2[donately.Account, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Account, error) {
+		return c.ListAccounts(ctx, offset, limit)
+	})
+}
+
+func (c *donatelyClient) People(ctx context.Context, account donately.Account, filter PersonFilter) iter.Seq2[donately.Person, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Person, error) {
+		return c.ListPeople(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *donatelyClient) Donations(ctx context.Context, account donately.Account, filter DonationFilter) iter.Seq2[donately.Donation, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Donation, error) {
+		return c.ListDonations(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *donatelyClient) Fundraisers(ctx context.Context, account donately.Account, filter FundraiserFilter) iter.Seq2[donately.Fundraiser, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Fundraiser, error) {
+		return c.ListFundraisers(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *donatelyClient) Subscriptions(ctx context.Context, account donately.Account, filter SubscriptionFilter) iter.Seq2[donately.Subscription, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Subscription, error) {
+		return c.ListSubscriptions(ctx, account, filter, offset, limit)
+	})
+}
+
+func (c *donatelyClient) Campaigns(ctx context.Context, account donately.Account, filter CampaignFilter) iter.Seq2[donately.Campaign, error] {
+	return paginate(ctx, defaultPageSize, c.concurrency, func(ctx context.Context, offset, limit int) ([]donately.Campaign, error) {
+		return c.ListCampaigns(ctx, account, filter, offset, limit)
+	})
+}
+
+// maxEmailLookups is the most addresses PeopleByEmail looks up one by one.
+// Past it, a single paged scan of the account costs fewer requests.
+const maxEmailLookups = 10
+
+// PeopleByEmail finds the people with the given addresses. A handful are looked
+// up with an exact email filter, as many at once as the client's concurrency
+// allows; more than that are matched during one scan of the account. The
+// result is keyed by lowercased email; addresses with no matching person are
+// absent.
+func PeopleByEmail(ctx context.Context, client Client, account donately.Account, emails []string) (map[string]donately.Person, error) {
+	var keys []string
+	seen := map[string]bool{}
+
+	for _, email := range emails {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if key == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	if len(keys) > maxEmailLookups {
+		return scanPeopleByEmail(ctx, client, account, seen)
+	}
+
+	return lookUpPeopleByEmail(ctx, client, account, keys)
+}
+
+func scanPeopleByEmail(ctx context.Context, client Client, account donately.Account, wanted map[string]bool) (map[string]donately.Person, error) {
+	found := map[string]donately.Person{}
+
+	for person, err := range client.People(ctx, account, PersonFilter{}) {
+		if err != nil {
+			return found, err
+		}
+
+		key := strings.ToLower(person.Email)
+		if _, dup := found[key]; wanted[key] && !dup {
+			found[key] = person
+		}
+	}
+
+	return found, nil
+}
+
+func lookUpPeopleByEmail(ctx context.Context, client Client, account donately.Account, keys []string) (map[string]donately.Person, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	people := make([]*donately.Person, len(keys))
+	errs := make([]error, len(keys))
+	slots := make(chan struct{}, concurrencyOf(client))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		slots <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			// One page is plenty for an exact match, and stops a server that
+			// ignores the filter from walking us through the whole account.
+			page, err := client.ListPeople(ctx, account, PersonFilter{Email: key}, 0, defaultPageSize)
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+
+			for _, person := range page {
+				if strings.EqualFold(person.Email, key) {
+					people[i] = &person
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	found := map[string]donately.Person{}
+
+	for i, key := range keys {
+		if people[i] != nil {
+			found[key] = *people[i]
+		}
+	}
+
+	// Lookups cancelled because another one failed report context.Canceled,
+	// so surface the failure that started it.
+	var firstErr error
+	for _, err := range errs {
+		if err != nil && (firstErr == nil || errors.Is(firstErr, context.Canceled)) {
+			firstErr = err
+		}
+	}
+
+	return found, firstErr
+}
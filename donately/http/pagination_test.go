@@ -0,0 +1,262 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePages serves the records 0..total-1 a page at a time, remembering which
+// offsets were asked for.
+type fakePages struct {
+	total int
+	errAt int // offset that fails, or -1
+	delay func(offset int) time.Duration
+
+	mu      sync.Mutex
+	offsets []int
+}
+
+var errPage = errors.New("page failed")
+
+func (f *fakePages) fetch(ctx context.Context, offset, limit int) ([]int, error) {
+	f.mu.Lock()
+	f.offsets = append(f.offsets, offset)
+	f.mu.Unlock()
+
+	if f.delay != nil {
+		time.Sleep(f.delay(offset))
+	}
+
+	if offset == f.errAt {
+		return nil, errPage
+	}
+
+	var page []int
+	for i := offset; i < offset+limit && i < f.total; i++ {
+		page = append(page, i)
+	}
+
+	return page, nil
+}
+
+func (f *fakePages) requested() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	offsets := slices.Clone(f.offsets)
+	slices.Sort(offsets)
+	return offsets
+}
+
+func sequence(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func TestPaginate(t *testing.T) {
+	const pageSize = 10
+
+	tests := []struct {
+		name        string
+		total       int
+		errAt       int
+		concurrency int
+		stopAfter   int // stop ranging after this many records, 0 for never
+		want        []int
+		wantErr     error
+		wantOffsets []int
+	}{
+		{
+			name:        "empty first page",
+			total:       0,
+			errAt:       -1,
+			concurrency: 1,
+			want:        nil,
+			wantOffsets: []int{0},
+		},
+		{
+			name:        "empty first page, concurrently",
+			total:       0,
+			errAt:       -1,
+			concurrency: 4,
+			want:        nil,
+			wantOffsets: []int{0},
+		},
+		{
+			name:        "short first page, concurrently",
+			total:       7,
+			errAt:       -1,
+			concurrency: 4,
+			want:        sequence(7),
+			wantOffsets: []int{0},
+		},
+		{
+			// One at a time, offsets advance by what each page held and only
+			// an empty page ends the walk.
+			name:        "short last page",
+			total:       25,
+			errAt:       -1,
+			concurrency: 1,
+			want:        sequence(25),
+			wantOffsets: []int{0, 10, 20, 25},
+		},
+		{
+			name:        "short last page, concurrently",
+			total:       25,
+			errAt:       -1,
+			concurrency: 4,
+			want:        sequence(25),
+			wantOffsets: []int{0, 10, 20, 30, 40},
+		},
+		{
+			name:        "exact multiple of the page size",
+			total:       20,
+			errAt:       -1,
+			concurrency: 1,
+			want:        sequence(20),
+			wantOffsets: []int{0, 10, 20},
+		},
+		{
+			name:        "error mid-stream",
+			total:       50,
+			errAt:       20,
+			concurrency: 1,
+			want:        sequence(20),
+			wantErr:     errPage,
+			wantOffsets: []int{0, 10, 20},
+		},
+		{
+			name:        "error mid-stream, concurrently",
+			total:       50,
+			errAt:       20,
+			concurrency: 4,
+			want:        sequence(20),
+			wantErr:     errPage,
+			wantOffsets: []int{0, 10, 20, 30, 40},
+		},
+		{
+			name:        "stopping early",
+			total:       50,
+			errAt:       -1,
+			concurrency: 1,
+			stopAfter:   15,
+			want:        sequence(15),
+			wantOffsets: []int{0, 10},
+		},
+		{
+			name:        "stopping early, concurrently",
+			total:       50,
+			errAt:       -1,
+			concurrency: 4,
+			stopAfter:   5,
+			want:        sequence(5),
+			wantOffsets: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := &fakePages{total: tt.total, errAt: tt.errAt}
+
+			var got []int
+			var gotErr error
+
+			for record, err := range paginate(context.Background(), pageSize, tt.concurrency, pages.fetch) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+
+				got = append(got, record)
+				if len(got) == tt.stopAfter {
+					break
+				}
+			}
+
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("error = %v, want %v", gotErr, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+
+			if offsets := pages.requested(); !slices.Equal(offsets, tt.wantOffsets) {
+				t.Errorf("requested offsets = %v, want %v", offsets, tt.wantOffsets)
+			}
+		})
+	}
+}
+
+func TestPaginateConcurrentlyKeepsOffsetOrder(t *testing.T) {
+	// Later pages answer first, so anything yielded as it arrives comes out of
+	// order.
+	pages := &fakePages{
+		total: 95,
+		errAt: -1,
+		delay: func(offset int) time.Duration {
+			return time.Duration(100-offset) * 100 * time.Microsecond
+		},
+	}
+
+	got, err := Collect(paginate(context.Background(), 10, 4, pages.fetch))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, sequence(95)) {
+		t.Errorf("records = %v, want 0..94 in order", got)
+	}
+}
+
+func TestPaginateDoesNotSkipRecordsBetweenPages(t *testing.T) {
+	// Offsets used to advance by one past each page, dropping the first record
+	// of every page after the first.
+	tests := []struct {
+		concurrency int
+		wantOffsets []int
+	}{
+		{concurrency: 1, wantOffsets: []int{0, 10, 20, 30, 31}},
+		{concurrency: 3, wantOffsets: []int{0, 10, 20, 30}},
+	}
+
+	for _, tt := range tests {
+		pages := &fakePages{total: 31, errAt: -1}
+
+		got, err := Collect(paginate(context.Background(), 10, tt.concurrency, pages.fetch))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(got, sequence(31)) {
+			t.Errorf("concurrency %d: records = %v, want 0..30", tt.concurrency, got)
+		}
+
+		if offsets := pages.requested(); !slices.Equal(offsets, tt.wantOffsets) {
+			t.Errorf("concurrency %d: requested offsets = %v, want %v", tt.concurrency, offsets, tt.wantOffsets)
+		}
+	}
+}
+
+func TestPaginateStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pages := &fakePages{total: 50, errAt: -1}
+
+	_, err := Collect(paginate(ctx, 10, 1, pages.fetch))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+
+	if offsets := pages.requested(); len(offsets) != 0 {
+		t.Errorf("requested offsets = %v, want none", offsets)
+	}
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit describes a token bucket: a steady refill rate and how many
+// requests may be issued back to back before callers start waiting.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func DefaultRateLimit() RateLimit {
+	return RateLimit{
+		RequestsPerSecond: 5,
+		Burst:             10,
+	}
+}
+
+// slowWaitThreshold is the wait above which a throttled request is reported.
+const slowWaitThreshold = 250 * time.Millisecond
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*rate.Limiter{}
+)
+
+// limiterFor returns the limiter shared by every client using apiKey, so
+// concurrent callers draw from the same bucket. Asking again with a different
+// limit reconfigures the shared bucket.
+func limiterFor(apiKey string, limit RateLimit) *rate.Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	every := rate.Inf
+	if limit.RequestsPerSecond > 0 {
+		every = rate.Limit(limit.RequestsPerSecond)
+	}
+
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	limiter, present := limiters[apiKey]
+	if !present {
+		limiter = rate.NewLimiter(every, burst)
+		limiters[apiKey] = limiter
+		return limiter
+	}
+
+	limiter.SetLimit(every)
+	limiter.SetBurst(burst)
+
+	return limiter
+}
+
+func (c *donatelyClient) waitForRateLimit(ctx context.Context, logger *slog.Logger) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	startedAt := time.Now()
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limiter: %w", err)
+	}
+
+	if waited := time.Since(startedAt); waited >= slowWaitThreshold {
+		logger.Info("rate limiter delayed donately request", slog.Duration("wait", waited.Round(time.Millisecond)))
+	}
+
+	return nil
+}
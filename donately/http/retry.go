@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+// RetryPolicy controls how transient Donately failures are retried.
+type RetryPolicy struct {
+	MaxAttempts     int
+	MaxElapsedTime  time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     5,
+		MaxElapsedTime:  2 * time.Minute,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     30 * time.Second,
+	}
+}
+
+// retryableError marks a failure as transient. rejected indicates that the
+// server refused the request before processing it (e.g. it was throttled or
+// never reached), which makes it safe to retry even non-idempotent requests.
+type retryableError struct {
+	Err        error
+	rejected   bool
+	retryAfter time.Duration
+}
+
+func (e retryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.Err
+}
+
+func (e retryableError) canRetry(method string) bool {
+	return e.rejected || isIdempotent(method)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isDialError reports whether err happened while establishing the connection,
+// in which case the request never left this process.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isRetryLater(body []byte) bool {
+	return strings.ToLower(strings.TrimSpace(string(body))) == "retry later"
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: a number
+// of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+func (p RetryPolicy) do(ctx context.Context, logger *slog.Logger, method string, attempt func(int) (*APIResponse, error)) (*APIResponse, error) {
+	b := backoff.NewExponentialBackOff()
+	if p.InitialInterval > 0 {
+		b.InitialInterval = p.InitialInterval
+	}
+	if p.MaxInterval > 0 {
+		b.MaxInterval = p.MaxInterval
+	}
+
+	startedAt := time.Now()
+
+	for n := 1; ; n++ {
+		resp, err := attempt(n)
+		if err == nil {
+			return resp, nil
+		}
+
+		var re retryableError
+		if !errors.As(err, &re) || !re.canRetry(method) {
+			return nil, err
+		}
+
+		if p.MaxAttempts > 0 && n >= p.MaxAttempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", n, err)
+		}
+
+		wait := re.retryAfter
+		if wait == 0 {
+			wait = b.NextBackOff()
+		}
+
+		if p.MaxElapsedTime > 0 && time.Since(startedAt)+wait > p.MaxElapsedTime {
+			return nil, fmt.Errorf("giving up after %v: %w", time.Since(startedAt).Round(time.Millisecond), err)
+		}
+
+		logger.Info("retrying donately request", slog.String("method", method), slog.Int("attempt", n+1), slog.Duration("wait", wait), slog.Any("error", err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errPermanent = errors.New("permanent")
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	retryable := retryableError{Err: errTransient}
+	rejected := retryableError{Err: errTransient, rejected: true}
+
+	tests := []struct {
+		name         string
+		method       string
+		policy       RetryPolicy
+		failures     []error // returned by successive attempts before succeeding
+		wantAttempts int
+		wantErr      error
+		wantGaveUp   string
+	}{
+		{
+			name:         "success on the first attempt",
+			method:       http.MethodGet,
+			wantAttempts: 1,
+		},
+		{
+			name:         "permanent errors are not retried",
+			method:       http.MethodGet,
+			failures:     []error{errPermanent},
+			wantAttempts: 1,
+			wantErr:      errPermanent,
+		},
+		{
+			name:         "idempotent requests retry transient errors",
+			method:       http.MethodGet,
+			failures:     []error{retryable, retryable},
+			wantAttempts: 3,
+		},
+		{
+			name:         "non-idempotent requests don't retry errors the server may have acted on",
+			method:       http.MethodPost,
+			failures:     []error{retryable},
+			wantAttempts: 1,
+			wantErr:      errTransient,
+		},
+		{
+			name:         "non-idempotent requests retry rejections",
+			method:       http.MethodPost,
+			failures:     []error{rejected, rejected},
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max attempts",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxAttempts: 2},
+			failures:     []error{retryable, retryable, retryable},
+			wantAttempts: 2,
+			wantErr:      errTransient,
+			wantGaveUp:   "giving up after 2 attempts",
+		},
+		{
+			name:         "gives up rather than wait past max elapsed time",
+			method:       http.MethodGet,
+			policy:       RetryPolicy{MaxElapsedTime: time.Second},
+			failures:     []error{retryableError{Err: errTransient, retryAfter: time.Hour}},
+			wantAttempts: 1,
+			wantErr:      errTransient,
+			wantGaveUp:   "giving up after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			policy.InitialInterval = time.Millisecond
+			policy.MaxInterval = time.Millisecond
+
+			attempts := 0
+			_, err := policy.do(context.Background(), discardLogger(), tt.method, func(n int) (*APIResponse, error) {
+				attempts++
+
+				if n != attempts {
+					t.Errorf("attempt numbered %d, want %d", n, attempts)
+				}
+
+				if n <= len(tt.failures) {
+					return nil, tt.failures[n-1]
+				}
+
+				return &APIResponse{}, nil
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantGaveUp != "" && (err == nil || !strings.Contains(err.Error(), tt.wantGaveUp)) {
+				t.Errorf("error = %v, want it to say %q", err, tt.wantGaveUp)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoStopsWaitingWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	policy := RetryPolicy{InitialInterval: time.Hour, MaxInterval: time.Hour}
+
+	_, err := policy.do(ctx, discardLogger(), http.MethodGet, func(int) (*APIResponse, error) {
+		cancel()
+		return nil, retryableError{Err: errTransient}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value   string
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{value: "", atMost: 0},
+		{value: "abc", atMost: 0},
+		{value: "0", atMost: 0},
+		{value: "-5", atMost: 0},
+		{value: " 3 ", atLeast: 3 * time.Second, atMost: 3 * time.Second},
+		{value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), atLeast: 58 * time.Second, atMost: time.Minute},
+		{value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), atMost: 0},
+	}
+
+	for _, tt := range tests {
+		got := parseRetryAfter(tt.value)
+		if got < tt.atLeast || got > tt.atMost {
+			t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.value, got, tt.atLeast, tt.atMost)
+		}
+	}
+}
+
+func TestRetryClassification(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+		http.StatusInternalServerError: false,
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+	} {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+
+	for method, want := range map[string]bool{
+		http.MethodGet:    true,
+		http.MethodPut:    true,
+		http.MethodDelete: true,
+		http.MethodPost:   false,
+		http.MethodPatch:  false,
+	} {
+		if got := isIdempotent(method); got != want {
+			t.Errorf("isIdempotent(%s) = %v, want %v", method, got, want)
+		}
+	}
+
+	if !isRetryLater([]byte(" Retry later\n")) || isRetryLater([]byte("retry later please")) {
+		t.Error("isRetryLater misread the body")
+	}
+
+	if !isDialError(&net.OpError{Op: "dial", Err: errTransient}) || isDialError(&net.OpError{Op: "read", Err: errTransient}) {
+		t.Error("isDialError misclassified a connection error")
+	}
+}
+
+// TestClientRetries checks how the client classifies real responses: server
+// errors and "retry later" bodies are retried only for idempotent requests,
+// 429s for any.
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		post         bool
+		wantAttempts int32
+	}{
+		{name: "unavailable GET", status: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "unavailable POST", status: http.StatusServiceUnavailable, post: true, wantAttempts: 1},
+		{name: "throttled POST", status: http.StatusTooManyRequests, post: true, wantAttempts: 3},
+		{name: "retry later GET", status: http.StatusOK, body: "Retry later", wantAttempts: 3},
+		{name: "retry later POST", status: http.StatusOK, body: "Retry later", post: true, wantAttempts: 1},
+		{name: "bad request GET", status: http.StatusBadRequest, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client, err := NewDonatelyClient(
+				WithBaseURL(srv.URL),
+				WithAPIKey("test"),
+				WithLogger(discardLogger()),
+				WithRateLimit(RateLimit{}),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account := donately.Account{ID: "act_1"}
+
+			if tt.post {
+				_, err = client.SavePerson(context.Background(), donately.Person{Email: "ada@example.com", Accounts: []donately.Account{account}})
+			} else {
+				_, err = client.FindAccount(context.Background(), account.ID)
+			}
+
+			if err == nil {
+				t.Fatal("request succeeded, want an error")
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
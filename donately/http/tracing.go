@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/willmadison/donately-sync-tools/donately/http")
+
+// startRequestSpan opens the span for one attempt at a Donately call. Spans
+// are no-ops unless a tracer provider has been installed.
+func startRequestSpan(ctx context.Context, method, endpoint string, attempt int) (context.Context, trace.Span) {
+	label := endpointLabel(endpoint)
+
+	return tracer.Start(ctx, "donately "+method+" "+label,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("donately.endpoint", label),
+			attribute.Int("donately.attempt", attempt),
+		),
+	)
+}
+
+func finishRequestSpan(span trace.Span, status int, requestID string, err error) {
+	if status > 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+
+	if requestID != "" {
+		span.SetAttributes(attribute.String("donately.request_id", requestID))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
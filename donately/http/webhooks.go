@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+const (
+	EventDonationCreated     = "donation.created"
+	EventDonationRefunded    = "donation.refunded"
+	EventSubscriptionUpdated = "subscription.updated"
+	EventPersonUpdated       = "person.updated"
+)
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the raw request body,
+// keyed with the webhook secret and optionally prefixed with "sha256=".
+const WebhookSignatureHeader = "X-Donately-Signature"
+
+const maxWebhookBytes = 1 << 20
+
+// WebhookEvent is the envelope Donately posts for every event. Data holds the
+// affected donation, subscription or person.
+type WebhookEvent struct {
+	ID      string            `json:"id"`
+	Type    string            `json:"type"`
+	Created donately.UnixTime `json:"created"`
+	Data    json.RawMessage   `json:"data"`
+}
+
+// VerifyWebhookSignature reports whether signature is body signed with secret.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// WebhookHandler receives Donately events and drops whatever client has cached
+// about their subject, so the next overview reflects them without rescanning
+// the account.
+func WebhookHandler(client Client, secret string, logger *slog.Logger) func(*gin.Context) {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unreadable body"})
+			return
+		}
+
+		if !VerifyWebhookSignature(secret, body, c.GetHeader(WebhookSignatureHeader)) {
+			webhookEvents.WithLabelValues("unknown", "rejected").Inc()
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+
+		var event WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			webhookEvents.WithLabelValues("unknown", "malformed").Inc()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "malformed event", "details": err.Error()})
+			return
+		}
+
+		handled, err := applyWebhookEvent(c.Request.Context(), client, event)
+		if err != nil {
+			webhookEvents.WithLabelValues(event.Type, "malformed").Inc()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "malformed event", "details": err.Error()})
+			return
+		}
+
+		if !handled {
+			webhookEvents.WithLabelValues(event.Type, "ignored").Inc()
+			logger.Info("ignoring donately webhook", "event_id", event.ID, "type", event.Type)
+			c.Status(http.StatusAccepted)
+			return
+		}
+
+		webhookEvents.WithLabelValues(event.Type, "applied").Inc()
+		logger.Info("applied donately webhook", "event_id", event.ID, "type", event.Type)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// applyWebhookEvent decodes event and invalidates the cache entries it makes
+// stale. It reports false for event types it doesn't know.
+func applyWebhookEvent(ctx context.Context, client Client, event WebhookEvent) (bool, error) {
+	switch event.Type {
+	case EventDonationCreated, EventDonationRefunded:
+		var donation donately.Donation
+		if err := json.Unmarshal(event.Data, &donation); err != nil {
+			return true, fmt.Errorf("failed to unmarshal donation: %w", err)
+		}
+
+		forget(ctx, client, accountPrefix(donationsKey, donation.Account), accountPrefix(campaignsKey, donation.Account))
+	case EventSubscriptionUpdated:
+		var subscription donately.Subscription
+		if err := json.Unmarshal(event.Data, &subscription); err != nil {
+			return true, fmt.Errorf("failed to unmarshal subscription: %w", err)
+		}
+
+		// Subscriptions aren't cached themselves, but the donations they
+		// generate embed them.
+		forget(ctx, client, accountPrefix(donationsKey, subscription.Account))
+	case EventPersonUpdated:
+		var person donately.Person
+		if err := json.Unmarshal(event.Data, &person); err != nil {
+			return true, fmt.Errorf("failed to unmarshal person: %w", err)
+		}
+
+		accounts := person.Accounts
+		if len(accounts) == 0 {
+			accounts = []donately.Account{{}}
+		}
+
+		for _, account := range accounts {
+			forget(ctx, client, accountPrefix(peopleKey, account), accountPrefix(donationsKey, account))
+		}
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// accountPrefix is key's cache prefix for account. Events that don't say which
+// account they belong to get the prefix for every account instead.
+func accountPrefix(key func(donately.Account) string, account donately.Account) string {
+	if account.ID == "" {
+		return strings.TrimSuffix(key(account), ":")
+	}
+
+	return key(account)
+}
+
+// forget invalidates cache entries under prefixes when client caches at all.
+func forget(ctx context.Context, client Client, prefixes ...string) {
+	if cache, ok := client.(*cachingClient); ok {
+		cache.invalidate(ctx, prefixes...)
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willmadison/donately-sync-tools/donately"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"donation.created"}`)
+	valid := sign(testWebhookSecret, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: testWebhookSecret, body: body, signature: valid, want: true},
+		{name: "sha256 prefix", secret: testWebhookSecret, body: body, signature: "sha256=" + valid, want: true},
+		{name: "uppercase hex", secret: testWebhookSecret, body: body, signature: strings.ToUpper(valid), want: true},
+		{name: "surrounding whitespace", secret: testWebhookSecret, body: body, signature: " " + valid + "\n", want: true},
+		{name: "wrong secret", secret: "whsec_other", body: body, signature: valid},
+		{name: "tampered body", secret: testWebhookSecret, body: append([]byte(" "), body...), signature: valid},
+		{name: "truncated signature", secret: testWebhookSecret, body: body, signature: valid[:32]},
+		{name: "not hex", secret: testWebhookSecret, body: body, signature: "not-a-signature"},
+		{name: "missing", secret: testWebhookSecret, body: body, signature: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyWebhookSignature(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifyWebhookSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cachedKeys := []string{
+		"donations:act_1:page",
+		"donations:act_2:page",
+		"campaign:act_1:cmp_1",
+		"campaign:act_2:cmp_2",
+		"people:act_1:page",
+		"account:act_1",
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		signature  string // defaults to a valid one
+		wantStatus int
+		wantKept   []string
+	}{
+		{
+			name:       "donation for an account",
+			body:       `{"id":"evt_1","type":"donation.created","data":{"id":"donation_1","account":{"id":"act_1"}}}`,
+			wantStatus: http.StatusNoContent,
+			wantKept:   []string{"donations:act_2:page", "campaign:act_2:cmp_2", "people:act_1:page", "account:act_1"},
+		},
+		{
+			name:       "donation without an account",
+			body:       `{"id":"evt_2","type":"donation.refunded","data":{"id":"donation_1"}}`,
+			wantStatus: http.StatusNoContent,
+			wantKept:   []string{"people:act_1:page", "account:act_1"},
+		},
+		{
+			name:       "person without accounts",
+			body:       `{"id":"evt_3","type":"person.updated","data":{"id":"person_1"}}`,
+			wantStatus: http.StatusNoContent,
+			wantKept:   []string{"campaign:act_1:cmp_1", "campaign:act_2:cmp_2", "account:act_1"},
+		},
+		{
+			name:       "unknown event",
+			body:       `{"id":"evt_4","type":"fundraiser.created","data":{}}`,
+			wantStatus: http.StatusAccepted,
+			wantKept:   cachedKeys,
+		},
+		{
+			name:       "bad signature",
+			body:       `{"id":"evt_5","type":"donation.created","data":{"account":{"id":"act_1"}}}`,
+			signature:  sign("whsec_other", []byte("{}")),
+			wantStatus: http.StatusUnauthorized,
+			wantKept:   cachedKeys,
+		},
+		{
+			name:       "malformed data",
+			body:       `{"id":"evt_6","type":"donation.created","data":"nope"}`,
+			wantStatus: http.StatusBadRequest,
+			wantKept:   cachedKeys,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			store := donately.NewMemoryCacheStore()
+			for _, key := range cachedKeys {
+				if err := store.SaveCacheEntry(ctx, key, []byte("{}")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			client := NewCachingClient(nil, store, time.Minute, discardLogger())
+
+			router := gin.New()
+			router.POST("/webhooks", WebhookHandler(client, testWebhookSecret, discardLogger()))
+
+			signature := tt.signature
+			if signature == "" {
+				signature = sign(testWebhookSecret, []byte(tt.body))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set(WebhookSignatureHeader, signature)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			kept := map[string]bool{}
+			for _, key := range tt.wantKept {
+				kept[key] = true
+			}
+
+			for _, key := range cachedKeys {
+				_, _, ok, err := store.GetCacheEntry(ctx, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if ok != kept[key] {
+					t.Errorf("%s cached = %v, want %v", key, ok, kept[key])
+				}
+			}
+		})
+	}
+}
@@ -8,9 +8,28 @@ import (
 	"database/sql"
 )
 
+type CacheEntry struct {
+	Key      string
+	Value    []byte
+	StoredAt int64
+}
+
+type DonationLedger struct {
+	IdempotencyKey string
+	PersonID       string
+	CampaignID     string
+	AmountInCents  int64
+	SourceRow      string
+	DonationID     sql.NullString
+	Status         string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
 type DonorAdjustment struct {
-	PersonID    sql.NullString
-	DisplayName sql.NullString
-	Slug        sql.NullString
-	Amount      sql.NullFloat64
+	PersonID      sql.NullString
+	DisplayName   sql.NullString
+	Slug          sql.NullString
+	AmountInCents sql.NullInt64
+	Currency      sql.NullString
 }
@@ -10,8 +10,146 @@ import (
 	"database/sql"
 )
 
+const abandonDonationLedgerEntry = `-- name: AbandonDonationLedgerEntry :exec
+DELETE FROM donation_ledger
+WHERE idempotency_key = ?1 AND status = 'pending'
+`
+
+func (q *Queries) AbandonDonationLedgerEntry(ctx context.Context, idempotencyKey string) error {
+	_, err := q.db.ExecContext(ctx, abandonDonationLedgerEntry, idempotencyKey)
+	return err
+}
+
+const beginDonationLedgerEntry = `-- name: BeginDonationLedgerEntry :one
+INSERT INTO donation_ledger(
+    idempotency_key,
+    person_id,
+    campaign_id,
+    amount_in_cents,
+    source_row,
+    status,
+    created_at,
+    updated_at
+)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5,
+    'pending',
+    ?6,
+    ?6
+)
+ON CONFLICT(idempotency_key) DO
+UPDATE SET updated_at = ?6
+RETURNING idempotency_key, person_id, campaign_id, amount_in_cents, source_row, donation_id, status, created_at, updated_at
+`
+
+type BeginDonationLedgerEntryParams struct {
+	IdempotencyKey string
+	PersonID       string
+	CampaignID     string
+	AmountInCents  int64
+	SourceRow      string
+	CreatedAt      int64
+}
+
+func (q *Queries) BeginDonationLedgerEntry(ctx context.Context, arg BeginDonationLedgerEntryParams) (DonationLedger, error) {
+	row := q.db.QueryRowContext(ctx, beginDonationLedgerEntry,
+		arg.IdempotencyKey,
+		arg.PersonID,
+		arg.CampaignID,
+		arg.AmountInCents,
+		arg.SourceRow,
+		arg.CreatedAt,
+	)
+	var i DonationLedger
+	err := row.Scan(
+		&i.IdempotencyKey,
+		&i.PersonID,
+		&i.CampaignID,
+		&i.AmountInCents,
+		&i.SourceRow,
+		&i.DonationID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const commitDonationLedgerEntry = `-- name: CommitDonationLedgerEntry :exec
+UPDATE donation_ledger
+SET donation_id = ?2,
+    status = 'committed',
+    updated_at = ?3
+WHERE idempotency_key = ?1
+`
+
+type CommitDonationLedgerEntryParams struct {
+	IdempotencyKey string
+	DonationID     sql.NullString
+	UpdatedAt      int64
+}
+
+func (q *Queries) CommitDonationLedgerEntry(ctx context.Context, arg CommitDonationLedgerEntryParams) error {
+	_, err := q.db.ExecContext(ctx, commitDonationLedgerEntry, arg.IdempotencyKey, arg.DonationID, arg.UpdatedAt)
+	return err
+}
+
+const deleteCacheEntriesByPrefix = `-- name: DeleteCacheEntriesByPrefix :exec
+DELETE FROM cache_entries
+WHERE substr(key, 1, length(?1)) = ?1
+`
+
+func (q *Queries) DeleteCacheEntriesByPrefix(ctx context.Context, prefix string) error {
+	_, err := q.db.ExecContext(ctx, deleteCacheEntriesByPrefix, prefix)
+	return err
+}
+
+const getCacheEntry = `-- name: GetCacheEntry :one
+SELECT key, value, stored_at
+FROM cache_entries
+WHERE key = ?1
+`
+
+func (q *Queries) GetCacheEntry(ctx context.Context, key string) (CacheEntry, error) {
+	row := q.db.QueryRowContext(ctx, getCacheEntry, key)
+	var i CacheEntry
+	err := row.Scan(
+		&i.Key,
+		&i.Value,
+		&i.StoredAt,
+	)
+	return i, err
+}
+
+const getDonationLedgerEntry = `-- name: GetDonationLedgerEntry :one
+SELECT idempotency_key, person_id, campaign_id, amount_in_cents, source_row, donation_id, status, created_at, updated_at
+FROM donation_ledger
+WHERE idempotency_key = ?1
+`
+
+func (q *Queries) GetDonationLedgerEntry(ctx context.Context, idempotencyKey string) (DonationLedger, error) {
+	row := q.db.QueryRowContext(ctx, getDonationLedgerEntry, idempotencyKey)
+	var i DonationLedger
+	err := row.Scan(
+		&i.IdempotencyKey,
+		&i.PersonID,
+		&i.CampaignID,
+		&i.AmountInCents,
+		&i.SourceRow,
+		&i.DonationID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getDonorAdjustmentsByPerson = `-- name: GetDonorAdjustmentsByPerson :many
-SELECT person_id, display_name, slug, amount
+SELECT person_id, display_name, slug, amount_in_cents, currency
 FROM donor_adjustments
 WHERE person_id = ?1
 `
@@ -29,7 +167,8 @@ func (q *Queries) GetDonorAdjustmentsByPerson(ctx context.Context, personID sql.
 			&i.PersonID,
 			&i.DisplayName,
 			&i.Slug,
-			&i.Amount,
+			&i.AmountInCents,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -44,31 +183,62 @@ func (q *Queries) GetDonorAdjustmentsByPerson(ctx context.Context, personID sql.
 	return items, nil
 }
 
+const saveCacheEntry = `-- name: SaveCacheEntry :exec
+INSERT INTO cache_entries(
+    key,
+    value,
+    stored_at
+)
+VALUES (
+    ?1,
+    ?2,
+    ?3
+)
+ON CONFLICT(key) DO
+UPDATE SET value = ?2,
+           stored_at = ?3
+`
+
+type SaveCacheEntryParams struct {
+	Key      string
+	Value    []byte
+	StoredAt int64
+}
+
+func (q *Queries) SaveCacheEntry(ctx context.Context, arg SaveCacheEntryParams) error {
+	_, err := q.db.ExecContext(ctx, saveCacheEntry, arg.Key, arg.Value, arg.StoredAt)
+	return err
+}
+
 const saveDonorAdjustment = `-- name: SaveDonorAdjustment :one
 INSERT INTO donor_adjustments(
     person_id, 
     display_name, 
     slug,
-    amount 
+    amount_in_cents,
+    currency
 ) 
 VALUES (
     ?1, 
     ?2, 
     ?3,
-    ?4
+    ?4,
+    ?5
 ) 
 ON CONFLICT(person_id, slug) DO 
 UPDATE SET display_name = ?2, 
-           amount = ?4
+           amount_in_cents = ?4,
+           currency = ?5
 WHERE person_id = ?1 AND slug = ?3
-RETURNING person_id, display_name, slug, amount
+RETURNING person_id, display_name, slug, amount_in_cents, currency
 `
 
 type SaveDonorAdjustmentParams struct {
-	PersonID    sql.NullString
-	DisplayName sql.NullString
-	Slug        sql.NullString
-	Amount      sql.NullFloat64
+	PersonID      sql.NullString
+	DisplayName   sql.NullString
+	Slug          sql.NullString
+	AmountInCents sql.NullInt64
+	Currency      sql.NullString
 }
 
 func (q *Queries) SaveDonorAdjustment(ctx context.Context, arg SaveDonorAdjustmentParams) (DonorAdjustment, error) {
@@ -76,14 +246,16 @@ func (q *Queries) SaveDonorAdjustment(ctx context.Context, arg SaveDonorAdjustme
 		arg.PersonID,
 		arg.DisplayName,
 		arg.Slug,
-		arg.Amount,
+		arg.AmountInCents,
+		arg.Currency,
 	)
 	var i DonorAdjustment
 	err := row.Scan(
 		&i.PersonID,
 		&i.DisplayName,
 		&i.Slug,
-		&i.Amount,
+		&i.AmountInCents,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -0,0 +1,33 @@
+// Package sqlite holds the goose migrations and sqlc queries backing the
+// donately package's stores.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Up runs the "+goose Up" section of the named migration against db. It is
+// only meant for migrations that are safe to re-run, e.g. for local database
+// files that are never migrated by goose itself.
+func Up(ctx context.Context, db *sql.DB, name string) error {
+	raw, err := migrations.ReadFile("migrations/" + name)
+	if err != nil {
+		return err
+	}
+
+	up, _, _ := strings.Cut(string(raw), "-- +goose Down")
+	up = strings.TrimPrefix(strings.TrimSpace(up), "-- +goose Up")
+
+	if _, err := db.ExecContext(ctx, up); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS donation_ledger (
+    idempotency_key VARCHAR PRIMARY KEY NOT NULL,
+    person_id VARCHAR NOT NULL,
+    campaign_id VARCHAR NOT NULL,
+    amount_in_cents INTEGER NOT NULL,
+    source_row VARCHAR NOT NULL,
+    donation_id VARCHAR,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS donation_ledger_person_id_idx ON donation_ledger (person_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS donation_ledger;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS cache_entries (
+    key VARCHAR PRIMARY KEY NOT NULL,
+    value BLOB NOT NULL,
+    stored_at INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS cache_entries;
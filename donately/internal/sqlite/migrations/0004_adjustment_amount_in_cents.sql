@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE donor_adjustments ADD COLUMN amount_in_cents INTEGER;
+ALTER TABLE donor_adjustments ADD COLUMN currency VARCHAR;
+UPDATE donor_adjustments SET amount_in_cents = CAST(ROUND(amount * 100) AS INTEGER) WHERE amount IS NOT NULL;
+ALTER TABLE donor_adjustments DROP COLUMN amount;
+
+-- +goose Down
+ALTER TABLE donor_adjustments ADD COLUMN amount REAL;
+UPDATE donor_adjustments SET amount = amount_in_cents / 100.0 WHERE amount_in_cents IS NOT NULL;
+ALTER TABLE donor_adjustments DROP COLUMN currency;
+ALTER TABLE donor_adjustments DROP COLUMN amount_in_cents;
@@ -8,16 +8,84 @@ INSERT INTO donor_adjustments(
     person_id, 
     display_name, 
     slug,
-    amount 
+    amount_in_cents,
+    currency
 ) 
 VALUES (
     ?1, 
     ?2, 
     ?3,
-    ?4
+    ?4,
+    ?5
 ) 
 ON CONFLICT(person_id, slug) DO 
 UPDATE SET display_name = ?2, 
-           amount = ?4
+           amount_in_cents = ?4,
+           currency = ?5
 WHERE person_id = ?1 AND slug = ?3
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetDonationLedgerEntry :one
+SELECT *
+FROM donation_ledger
+WHERE idempotency_key = ?1;
+
+-- name: BeginDonationLedgerEntry :one
+INSERT INTO donation_ledger(
+    idempotency_key,
+    person_id,
+    campaign_id,
+    amount_in_cents,
+    source_row,
+    status,
+    created_at,
+    updated_at
+)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5,
+    'pending',
+    ?6,
+    ?6
+)
+ON CONFLICT(idempotency_key) DO
+UPDATE SET updated_at = ?6
+RETURNING *;
+
+-- name: CommitDonationLedgerEntry :exec
+UPDATE donation_ledger
+SET donation_id = ?2,
+    status = 'committed',
+    updated_at = ?3
+WHERE idempotency_key = ?1;
+
+-- name: AbandonDonationLedgerEntry :exec
+DELETE FROM donation_ledger
+WHERE idempotency_key = ?1 AND status = 'pending';
+
+-- name: GetCacheEntry :one
+SELECT *
+FROM cache_entries
+WHERE key = ?1;
+
+-- name: SaveCacheEntry :exec
+INSERT INTO cache_entries(
+    key,
+    value,
+    stored_at
+)
+VALUES (
+    ?1,
+    ?2,
+    ?3
+)
+ON CONFLICT(key) DO
+UPDATE SET value = ?2,
+           stored_at = ?3;
+
+-- name: DeleteCacheEntriesByPrefix :exec
+DELETE FROM cache_entries
+WHERE substr(key, 1, length(?1)) = ?1;
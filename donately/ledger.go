@@ -0,0 +1,174 @@
+package donately
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willmadison/donately-sync-tools/donately/internal/sqlite"
+	"github.com/willmadison/donately-sync-tools/donately/internal/sqlite/donors"
+)
+
+// idempotencyKeyPrefix marks the tracking code that carries a donation's
+// idempotency key, so it can be told apart from any other tracking codes.
+const idempotencyKeyPrefix = "dst_"
+
+// DonationIdempotencyKey derives a stable key for creating donation on behalf
+// of sourceRow, so reruns and retries recognize gifts they already recorded.
+func DonationIdempotencyKey(donation Donation, sourceRow string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		donation.Person.ID,
+		donation.Campaign.ID,
+		strconv.FormatInt(donation.AmountInCents, 10),
+		sourceRow,
+	}, "\x00")))
+
+	return idempotencyKeyPrefix + hex.EncodeToString(sum[:16])
+}
+
+// IdempotencyKey returns the idempotency key carried in the donation's
+// tracking codes, if any.
+func (d Donation) IdempotencyKey() string {
+	for _, code := range strings.FieldsFunc(d.TrackingCodes, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if strings.HasPrefix(code, idempotencyKeyPrefix) {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// WithIdempotencyKey returns a copy of the donation whose tracking codes carry
+// key alongside any existing codes.
+func (d Donation) WithIdempotencyKey(key string) Donation {
+	if d.TrackingCodes == "" {
+		d.TrackingCodes = key
+	} else {
+		d.TrackingCodes += "," + key
+	}
+
+	return d
+}
+
+type LedgerStatus string
+
+const (
+	LedgerPending   LedgerStatus = "pending"
+	LedgerCommitted LedgerStatus = "committed"
+)
+
+// LedgerEntry records an attempt to create a donation. Entries are written as
+// pending before the request is sent and committed once Donately confirms it.
+type LedgerEntry struct {
+	IdempotencyKey string
+	PersonID       string
+	CampaignID     string
+	AmountInCents  int64
+	SourceRow      string
+	DonationID     string
+	Status         LedgerStatus
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DonationLedger is a write-ahead log of donation creates.
+type DonationLedger interface {
+	FindLedgerEntry(ctx context.Context, key string) (LedgerEntry, bool, error)
+	BeginLedgerEntry(context.Context, LedgerEntry) (LedgerEntry, error)
+	CommitLedgerEntry(ctx context.Context, key, donationID string) error
+	AbandonLedgerEntry(ctx context.Context, key string) error
+	Close() error
+}
+
+type sqliteDonationLedger struct {
+	db      *sql.DB
+	queries *donors.Queries
+}
+
+func (l sqliteDonationLedger) FindLedgerEntry(ctx context.Context, key string) (LedgerEntry, bool, error) {
+	entry, err := l.queries.GetDonationLedgerEntry(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return LedgerEntry{}, false, nil
+	}
+	if err != nil {
+		return LedgerEntry{}, false, fmt.Errorf("encountered an error reading the donation ledger: %w", err)
+	}
+
+	return asLedgerEntry(entry), true, nil
+}
+
+func (l sqliteDonationLedger) BeginLedgerEntry(ctx context.Context, entry LedgerEntry) (LedgerEntry, error) {
+	saved, err := l.queries.BeginDonationLedgerEntry(ctx, donors.BeginDonationLedgerEntryParams{
+		IdempotencyKey: entry.IdempotencyKey,
+		PersonID:       entry.PersonID,
+		CampaignID:     entry.CampaignID,
+		AmountInCents:  entry.AmountInCents,
+		SourceRow:      entry.SourceRow,
+		CreatedAt:      time.Now().Unix(),
+	})
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("encountered an error writing to the donation ledger: %w", err)
+	}
+
+	return asLedgerEntry(saved), nil
+}
+
+func (l sqliteDonationLedger) CommitLedgerEntry(ctx context.Context, key, donationID string) error {
+	err := l.queries.CommitDonationLedgerEntry(ctx, donors.CommitDonationLedgerEntryParams{
+		IdempotencyKey: key,
+		DonationID:     sql.NullString{String: donationID, Valid: donationID != ""},
+		UpdatedAt:      time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("encountered an error committing to the donation ledger: %w", err)
+	}
+
+	return nil
+}
+
+func (l sqliteDonationLedger) AbandonLedgerEntry(ctx context.Context, key string) error {
+	if err := l.queries.AbandonDonationLedgerEntry(ctx, key); err != nil {
+		return fmt.Errorf("encountered an error abandoning a donation ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+func (l sqliteDonationLedger) Close() error {
+	return l.db.Close()
+}
+
+func asLedgerEntry(entry donors.DonationLedger) LedgerEntry {
+	return LedgerEntry{
+		IdempotencyKey: entry.IdempotencyKey,
+		PersonID:       entry.PersonID,
+		CampaignID:     entry.CampaignID,
+		AmountInCents:  entry.AmountInCents,
+		SourceRow:      entry.SourceRow,
+		DonationID:     entry.DonationID.String,
+		Status:         LedgerStatus(entry.Status),
+		CreatedAt:      time.Unix(entry.CreatedAt, 0),
+		UpdatedAt:      time.Unix(entry.UpdatedAt, 0),
+	}
+}
+
+// NewDonationLedger opens (creating if needed) the SQLite ledger at path.
+func NewDonationLedger(ctx context.Context, path string) (DonationLedger, error) {
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return nil, fmt.Errorf("encountered an error opening the donation ledger: %s", err)
+	}
+
+	if err := sqlite.Up(ctx, db, "0002_donation_ledger.sql"); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sqliteDonationLedger{db: db, queries: donors.New(db)}, nil
+}
@@ -0,0 +1,115 @@
+package donately
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func testLedger(t *testing.T) DonationLedger {
+	t.Helper()
+
+	ledger, err := NewDonationLedger(context.Background(), filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+
+	return ledger
+}
+
+func TestDonationIdempotencyKey(t *testing.T) {
+	donation := Donation{Person: Person{ID: "person_1"}, Campaign: Campaign{ID: "cmp_1"}, AmountInCents: 2500}
+
+	key := DonationIdempotencyKey(donation, "ada@example.com|25.00")
+
+	if key != DonationIdempotencyKey(donation, "ada@example.com|25.00") {
+		t.Error("the same gift got two different keys")
+	}
+
+	other := donation
+	other.AmountInCents = 2600
+
+	if key == DonationIdempotencyKey(other, "ada@example.com|25.00") || key == DonationIdempotencyKey(donation, "ada@example.com|26.00") {
+		t.Error("different gifts share a key")
+	}
+
+	tagged := Donation{TrackingCodes: "spring-drive"}.WithIdempotencyKey(key)
+
+	if tagged.TrackingCodes != "spring-drive,"+key {
+		t.Errorf("tracking codes = %q, want the key added to the existing code", tagged.TrackingCodes)
+	}
+
+	if got := tagged.IdempotencyKey(); got != key {
+		t.Errorf("IdempotencyKey = %q, want %q", got, key)
+	}
+
+	if got := (Donation{TrackingCodes: "spring-drive"}).IdempotencyKey(); got != "" {
+		t.Errorf("IdempotencyKey without one = %q, want none", got)
+	}
+}
+
+func TestDonationLedger(t *testing.T) {
+	ctx := context.Background()
+	ledger := testLedger(t)
+
+	entry := LedgerEntry{IdempotencyKey: "dst_1", PersonID: "person_1", CampaignID: "cmp_1", AmountInCents: 2500, SourceRow: "ada@example.com|25.00"}
+
+	if _, found, err := ledger.FindLedgerEntry(ctx, entry.IdempotencyKey); err != nil || found {
+		t.Fatalf("FindLedgerEntry before beginning = %v, %v, want nothing", found, err)
+	}
+
+	begun, err := ledger.BeginLedgerEntry(ctx, entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if begun.Status != LedgerPending || begun.AmountInCents != 2500 {
+		t.Errorf("begun entry = %+v, want a pending $25.00 entry", begun)
+	}
+
+	// An attempt that may have reached Donately is left pending for the next
+	// run to check, and beginning it again keeps it that way.
+	if begun, err = ledger.BeginLedgerEntry(ctx, entry); err != nil || begun.Status != LedgerPending {
+		t.Fatalf("begun again = %+v, %v, want it still pending", begun, err)
+	}
+
+	if err := ledger.CommitLedgerEntry(ctx, entry.IdempotencyKey, "donation_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Beginning a committed entry again must not reopen it.
+	if _, err := ledger.BeginLedgerEntry(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ledger.AbandonLedgerEntry(ctx, entry.IdempotencyKey); err != nil {
+		t.Fatal(err)
+	}
+
+	committed, found, err := ledger.FindLedgerEntry(ctx, entry.IdempotencyKey)
+	if err != nil || !found {
+		t.Fatalf("FindLedgerEntry after committing = %v, %v", found, err)
+	}
+
+	if committed.Status != LedgerCommitted || committed.DonationID != "donation_1" {
+		t.Errorf("committed entry = %+v, want it committed to donation_1", committed)
+	}
+}
+
+func TestAbandonLedgerEntry(t *testing.T) {
+	ctx := context.Background()
+	ledger := testLedger(t)
+
+	if _, err := ledger.BeginLedgerEntry(ctx, LedgerEntry{IdempotencyKey: "dst_1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ledger.AbandonLedgerEntry(ctx, "dst_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found, err := ledger.FindLedgerEntry(ctx, "dst_1"); err != nil || found {
+		t.Errorf("FindLedgerEntry after abandoning = %v, %v, want nothing", found, err)
+	}
+}
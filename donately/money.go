@@ -0,0 +1,249 @@
+package donately
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount counted in the minor unit of its currency (cents
+// for USD), so sums and differences never drift the way float dollars do.
+//
+// Currency is a lowercase ISO 4217 code as Donately reports it. An empty
+// currency means "whatever the surrounding account uses": it combines with any
+// other amount and adopts that amount's currency. Combining two different
+// non-empty currencies is a programming error and panics.
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+// NewMoney returns cents of currency.
+func NewMoney(cents int64, currency string) Money {
+	return Money{Cents: cents, Currency: strings.ToLower(currency)}
+}
+
+// ParseMoney reads amounts the way people write them, e.g. "1234.5",
+// "$1,234.56", "$ 5", "-$3.00" or "(3.00)". Commas must group thousands.
+// Blank input is zero. More than two decimal places is an error rather than a
+// rounding.
+func ParseMoney(s, currency string) (Money, error) {
+	raw := strings.TrimSpace(s)
+	value := raw
+
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	if strings.HasPrefix(value, "-") {
+		negative = !negative
+		value = strings.TrimSpace(value[1:])
+	}
+
+	value = strings.TrimSpace(strings.TrimPrefix(value, "$"))
+
+	if value == "" {
+		if raw != "" && raw != "$" {
+			return Money{}, fmt.Errorf("invalid amount %q", s)
+		}
+		return NewMoney(0, currency), nil
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+
+	whole, ok := ungroup(whole)
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q: misplaced thousands separator", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if len(fraction) > 2 {
+		return Money{}, fmt.Errorf("invalid amount %q: more than two decimal places", s)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	if !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-99)/100 {
+		return Money{}, fmt.Errorf("invalid amount %q: out of range", s)
+	}
+
+	cents, _ := strconv.ParseInt(fraction, 10, 64)
+	cents += units * 100
+
+	if negative {
+		cents = -cents
+	}
+
+	return NewMoney(cents, currency), nil
+}
+
+// ungroup strips thousands separators from whole, reporting false unless
+// they fall every three digits, as in "1,234,567".
+func ungroup(whole string) (string, bool) {
+	groups := strings.Split(whole, ",")
+	if len(groups) == 1 {
+		return whole, true
+	}
+
+	if len(groups[0]) < 1 || len(groups[0]) > 3 {
+		return "", false
+	}
+
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", false
+		}
+	}
+
+	return strings.Join(groups, ""), true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+func (m Money) IsZero() bool     { return m.Cents == 0 }
+func (m Money) IsPositive() bool { return m.Cents > 0 }
+func (m Money) IsNegative() bool { return m.Cents < 0 }
+
+func (m Money) Neg() Money {
+	return Money{Cents: -m.Cents, Currency: m.Currency}
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{Cents: m.Cents + other.Cents, Currency: m.currencyWith(other)}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Cents: m.Cents - other.Cents, Currency: m.currencyWith(other)}
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	m.currencyWith(other)
+
+	switch {
+	case m.Cents < other.Cents:
+		return -1
+	case m.Cents > other.Cents:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) currencyWith(other Money) string {
+	switch {
+	case m.Currency == "":
+		return other.Currency
+	case other.Currency == "" || strings.EqualFold(m.Currency, other.Currency):
+		return m.Currency
+	default:
+		panic(fmt.Sprintf("donately: cannot combine %s and %s amounts", m.Currency, other.Currency))
+	}
+}
+
+// SumMoney adds up amounts, starting from zero.
+func SumMoney(amounts ...Money) Money {
+	var total Money
+
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+
+	return total
+}
+
+// Decimal formats m as a plain decimal in major units, e.g. "-1234.50".
+func (m Money) Decimal() string {
+	cents := m.Cents
+
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+	}
+
+	units, remainder := cents/100, cents%100
+	if units < 0 {
+		units = -units
+	}
+	if remainder < 0 {
+		remainder = -remainder
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, units, remainder)
+}
+
+// String formats m for people: "$1,234.56" for dollars, "1,234.56 EUR"
+// otherwise.
+func (m Money) String() string {
+	decimal := m.Decimal()
+
+	sign := ""
+	if strings.HasPrefix(decimal, "-") {
+		sign, decimal = "-", decimal[1:]
+	}
+
+	units, fraction, _ := strings.Cut(decimal, ".")
+
+	var grouped strings.Builder
+	for i, digit := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	amount := grouped.String() + "." + fraction
+
+	if m.Currency == "" || m.Currency == "usd" {
+		return sign + "$" + amount
+	}
+
+	return sign + amount + " " + strings.ToUpper(m.Currency)
+}
+
+// MarshalJSON encodes m as a number in major units, which is what the
+// dashboard has always read. The currency belongs to the enclosing account.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON accepts a number in major units or any string ParseMoney
+// does, keeping m's currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	raw := string(data)
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoney(raw, m.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
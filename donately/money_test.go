@@ -0,0 +1,143 @@
+package donately
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "$", want: 0},
+		{in: "0", want: 0},
+		{in: "5", want: 500},
+		{in: "1234.5", want: 123450},
+		{in: ".75", want: 75},
+		{in: "$1,234.56", want: 123456},
+		{in: "1,234,567", want: 123456700},
+		{in: "$ 5", want: 500},
+		{in: " $ 1,000.00 ", want: 100000},
+		{in: "-$3.00", want: -300},
+		{in: "(3.00)", want: -300},
+		{in: "($1,000)", want: -100000},
+		{in: "1,2,3", wantErr: true},
+		{in: "1,23", wantErr: true},
+		{in: "1234,567", wantErr: true},
+		{in: ",123", wantErr: true},
+		{in: "1,234,56", wantErr: true},
+		{in: "1.2,3", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "12.3.4", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in, "USD")
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseMoney(%q): %v", tt.in, err)
+			continue
+		}
+
+		if want := NewMoney(tt.want, "usd"); got != want {
+			t.Errorf("ParseMoney(%q) = %#v, want %#v", tt.in, got, want)
+		}
+	}
+}
+
+func TestMoneyFormatting(t *testing.T) {
+	tests := []struct {
+		money       Money
+		wantDecimal string
+		wantString  string
+	}{
+		{money: NewMoney(0, ""), wantDecimal: "0.00", wantString: "$0.00"},
+		{money: NewMoney(5, "usd"), wantDecimal: "0.05", wantString: "$0.05"},
+		{money: NewMoney(-5, "usd"), wantDecimal: "-0.05", wantString: "-$0.05"},
+		{money: NewMoney(123456, "usd"), wantDecimal: "1234.56", wantString: "$1,234.56"},
+		{money: NewMoney(-123456789, ""), wantDecimal: "-1234567.89", wantString: "-$1,234,567.89"},
+		{money: NewMoney(100000, "EUR"), wantDecimal: "1000.00", wantString: "1,000.00 EUR"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.wantDecimal {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.money, got, tt.wantDecimal)
+		}
+
+		if got := tt.money.String(); got != tt.wantString {
+			t.Errorf("%#v.String() = %q, want %q", tt.money, got, tt.wantString)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	pledge := NewMoney(100000, "usd")
+	gift := NewMoney(2550, "")
+
+	if got := pledge.Sub(gift); got != NewMoney(97450, "usd") {
+		t.Errorf("Sub = %#v, want 974.50 usd", got)
+	}
+
+	if got := gift.Add(pledge); got.Currency != "usd" {
+		t.Errorf("Add adopted currency %q, want usd", got.Currency)
+	}
+
+	if got := SumMoney(gift, gift, gift.Neg()); got != gift {
+		t.Errorf("SumMoney = %#v, want %#v", got, gift)
+	}
+
+	if pledge.Cmp(gift) != 1 || gift.Cmp(pledge) != -1 || gift.Cmp(gift) != 0 {
+		t.Error("Cmp ordered amounts incorrectly")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("combining usd and eur didn't panic")
+		}
+	}()
+
+	pledge.Add(NewMoney(1, "eur"))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Pledge Money `json:"pledge"`
+	}{NewMoney(-123450, "usd")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"pledge":-1234.50}`; string(encoded) != want {
+		t.Errorf("Marshal = %s, want %s", encoded, want)
+	}
+
+	for _, in := range []string{`1234.5`, `"$1,234.50"`, `"1234.50"`} {
+		m := Money{Currency: "cad"}
+		if err := json.Unmarshal([]byte(in), &m); err != nil {
+			t.Errorf("Unmarshal(%s): %v", in, err)
+			continue
+		}
+
+		if want := NewMoney(123450, "cad"); m != want {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", in, m, want)
+		}
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`"1,2,3"`), &m); err == nil {
+		t.Errorf("Unmarshal accepted misgrouped thousands as %#v", m)
+	}
+}
@@ -2,18 +2,18 @@ package donately
 
 type Person struct {
 	ID                          string    `json:"id"`
-	Email                       string    `json:"email"`
-	FirstName                   string    `json:"first_name"`
-	LastName                    string    `json:"last_name"`
-	PhoneNumber                 string    `json:"phone_number"`
-	StreetAddress               string    `json:"street_address"`
-	StreetAddress2              string    `json:"street_address_2"`
-	City                        string    `json:"city"`
-	State                       string    `json:"state"`
-	ZipCode                     string    `json:"zip_code"`
-	Country                     string    `json:"country"`
-	Created                     int64     `json:"created"`
-	Updated                     int64     `json:"updated"`
+	Email                       string    `json:"email" form:"email"`
+	FirstName                   string    `json:"first_name" form:"first_name"`
+	LastName                    string    `json:"last_name" form:"last_name"`
+	PhoneNumber                 string    `json:"phone_number" form:"phone_number"`
+	StreetAddress               string    `json:"street_address" form:"street_address"`
+	StreetAddress2              string    `json:"street_address_2" form:"street_address_2"`
+	City                        string    `json:"city" form:"city"`
+	State                       string    `json:"state" form:"state"`
+	ZipCode                     string    `json:"zip_code" form:"zip_code"`
+	Country                     string    `json:"country" form:"country"`
+	Created                     UnixTime  `json:"created"`
+	Updated                     UnixTime  `json:"updated"`
 	LastSignIn                  IPAddress `json:"last_sign_in"`
 	ConnectedToMultipleAccounts bool      `json:"connected_to_multiple_accounts"`
 	HasAdminRoles               bool      `json:"has_admin_roles"`
@@ -22,11 +22,11 @@ type Person struct {
 }
 
 type IPAddress struct {
-	IPAddress  *string `json:"ip_address"`
-	Object     string  `json:"object"`
-	City       *string `json:"city"`
-	State      *string `json:"state"`
-	Country    *string `json:"country"`
-	PostalCode *string `json:"postal_code"`
-	SignInTime int64   `json:"sign_in_time"`
+	IPAddress  *string  `json:"ip_address"`
+	Object     string   `json:"object"`
+	City       *string  `json:"city"`
+	State      *string  `json:"state"`
+	Country    *string  `json:"country"`
+	PostalCode *string  `json:"postal_code"`
+	SignInTime UnixTime `json:"sign_in_time"`
 }
@@ -0,0 +1,244 @@
+package donately
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnixTime is a Donately timestamp, sent as seconds since the epoch. Decoding
+// also accepts numeric strings, ISO 8601 dates and times, and null.
+type UnixTime int64
+
+// UnixTimeOf returns t as a UnixTime.
+func UnixTimeOf(t time.Time) UnixTime {
+	if t.IsZero() {
+		return 0
+	}
+
+	return UnixTime(t.Unix())
+}
+
+func (t UnixTime) IsZero() bool {
+	return t == 0
+}
+
+// Time returns t in UTC, or the zero time.Time when t is unset.
+func (t UnixTime) Time() time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(t), 0).UTC()
+}
+
+// In returns t in loc.
+func (t UnixTime) In(loc *time.Location) time.Time {
+	return t.Time().In(loc)
+}
+
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	raw := string(data)
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		*t = 0
+		return nil
+	}
+
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		*t = UnixTime(seconds)
+		return nil
+	}
+
+	parsed, err := parseTimestamp(raw)
+	if err != nil {
+		return err
+	}
+
+	*t = UnixTimeOf(parsed)
+	return nil
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	"01/02/2006",
+}
+
+// parseTimestamp reads the ISO-ish forms Donately has been seen to send.
+// Anything without an offset is taken as UTC.
+func parseTimestamp(raw string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", raw)
+}
+
+// Date is a calendar day with no time zone of its own, like a campaign's start
+// or end. It encodes as "2006-01-02" and decodes from that, from full
+// timestamps (keeping the day in their own offset), from unix seconds (UTC)
+// and from null.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the day t falls on in its location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// ParseDate reads a date in any of the forms Date decodes.
+func ParseDate(raw string) (Date, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Date{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return DateOf(UnixTime(seconds).Time()), nil
+	}
+
+	parsed, err := parseTimestamp(raw)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return DateOf(parsed), nil
+}
+
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns midnight at the start of d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+// FirstOfMonth returns the first day of d's month, which is how reports key
+// monthly totals.
+func (d Date) FirstOfMonth() Date {
+	return Date{Year: d.Year, Month: d.Month, Day: 1}
+}
+
+// Compare returns -1, 0 or +1 as d is before, the same day as or after other.
+func (d Date) Compare(other Date) int {
+	return d.In(time.UTC).Compare(other.In(time.UTC))
+}
+
+func (d Date) Before(other Date) bool { return d.Compare(other) < 0 }
+func (d Date) After(other Date) bool  { return d.Compare(other) > 0 }
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	raw := string(data)
+
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	return d.UnmarshalText([]byte(raw))
+}
+
+// Location is the account's time zone, falling back to UTC when Donately
+// doesn't report one Go knows.
+func (a Account) Location() *time.Location {
+	if a.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// DonationsByDay groups donations by the day they were given in loc.
+func DonationsByDay(donations []Donation, loc *time.Location) map[Date][]Donation {
+	return groupDonations(donations, func(d Donation) Date {
+		return d.DateIn(loc)
+	})
+}
+
+// DonationsByMonth groups donations by the month they were given in loc, keyed
+// by the first of the month.
+func DonationsByMonth(donations []Donation, loc *time.Location) map[Date][]Donation {
+	return groupDonations(donations, func(d Donation) Date {
+		return d.DateIn(loc).FirstOfMonth()
+	})
+}
+
+func groupDonations(donations []Donation, key func(Donation) Date) map[Date][]Donation {
+	grouped := map[Date][]Donation{}
+
+	for _, donation := range donations {
+		k := key(donation)
+		grouped[k] = append(grouped[k], donation)
+	}
+
+	return grouped
+}
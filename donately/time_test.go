@@ -0,0 +1,166 @@
+package donately
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUnixTimeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    UnixTime
+		wantErr bool
+	}{
+		{in: `1700000000`, want: 1700000000},
+		{in: `1700000000.75`, want: 1700000000},
+		{in: `"1700000000"`, want: 1700000000},
+		{in: `null`, want: 0},
+		{in: `""`, want: 0},
+		{in: `"2023-11-14T22:13:20Z"`, want: 1700000000},
+		{in: `"2023-11-14T17:13:20-05:00"`, want: 1700000000},
+		{in: `"2023-11-14T22:13:20"`, want: 1700000000},
+		{in: `"2023-11-14 22:13:20"`, want: 1700000000},
+		{in: `"2023-11-14"`, want: 1699920000},
+		{in: `"11/14/2023"`, want: 1699920000},
+		{in: `"next tuesday"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got UnixTime
+		err := json.Unmarshal([]byte(tt.in), &got)
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnixTimeRoundTrip(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Created UnixTime `json:"created"`
+	}{1700000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"created":1700000000}`; string(encoded) != want {
+		t.Errorf("Marshal = %s, want %s", encoded, want)
+	}
+
+	if got := UnixTimeOf(time.Time{}); !got.IsZero() {
+		t.Errorf("UnixTimeOf(zero) = %d, want 0", got)
+	}
+
+	if got := UnixTime(0).Time(); !got.IsZero() {
+		t.Errorf("UnixTime(0).Time() = %v, want the zero time", got)
+	}
+}
+
+func TestDateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: `"2024-03-09"`, want: Date{2024, time.March, 9}},
+		{in: `"03/09/2024"`, want: Date{2024, time.March, 9}},
+		// Timestamps keep the day in their own offset rather than UTC's.
+		{in: `"2024-03-09T23:30:00-05:00"`, want: Date{2024, time.March, 9}},
+		{in: `1709942400`, want: Date{2024, time.March, 9}},
+		{in: `"1709942400"`, want: Date{2024, time.March, 9}},
+		{in: `null`, want: Date{}},
+		{in: `""`, want: Date{}},
+		{in: `"March 9th"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got Date
+		err := json.Unmarshal([]byte(tt.in), &got)
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateMarshalJSON(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Start *Date `json:"start"`
+		End   Date  `json:"end"`
+	}{Start: &Date{2024, time.January, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"start":"2024-01-05","end":null}`; string(encoded) != want {
+		t.Errorf("Marshal = %s, want %s", encoded, want)
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	leap := Date{2024, time.February, 28}
+
+	if got := leap.AddDays(1); got != (Date{2024, time.February, 29}) {
+		t.Errorf("AddDays(1) = %v, want 2024-02-29", got)
+	}
+
+	if got := leap.AddDays(2); got != (Date{2024, time.March, 1}) {
+		t.Errorf("AddDays(2) = %v, want 2024-03-01", got)
+	}
+
+	if got := leap.FirstOfMonth(); got != (Date{2024, time.February, 1}) {
+		t.Errorf("FirstOfMonth = %v, want 2024-02-01", got)
+	}
+
+	if !leap.Before(leap.AddDays(1)) || !leap.After(leap.AddDays(-1)) || leap.Compare(leap) != 0 {
+		t.Error("dates compared out of order")
+	}
+}
+
+func TestDonationDateIn(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	// 2024-01-01T03:00:00Z is still New Year's Eve in New York.
+	donation := Donation{DonationDate: 1704078000}
+
+	if got := donation.DateIn(time.UTC); got != (Date{2024, time.January, 1}) {
+		t.Errorf("DateIn(UTC) = %v, want 2024-01-01", got)
+	}
+
+	if got := donation.DateIn(newYork); got != (Date{2023, time.December, 31}) {
+		t.Errorf("DateIn(New York) = %v, want 2023-12-31", got)
+	}
+
+	if got := (Account{Timezone: "Not/AZone"}).Location(); got != time.UTC {
+		t.Errorf("Location for an unknown zone = %v, want UTC", got)
+	}
+}